@@ -50,7 +50,8 @@ def get_pois_from_file(file_path, categories):
                     "lat": poi["lat"],
                     "satisfaction": categories[cat],
                     "openHour": poi["openHour"],
-                    "closeHour": poi["closeHour"]
+                    "closeHour": poi["closeHour"],
+                    "categories": poi["categories"]
                 }
                 result.append(new_poi)
                 used_poi.append(poi["name"])
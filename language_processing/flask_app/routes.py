@@ -61,7 +61,8 @@ def travel_plan():
                         "lat": poi["lat"],
                         "satisfaction": extracted_categories[cat],
                         "openHour": poi["openHour"],
-                        "closeHour": poi["closeHour"]
+                        "closeHour": poi["closeHour"],
+                        "categories": poi["categories"]
                     }
                     pois_pool.append(new_poi)
                     used_poi.append(poi["name"])
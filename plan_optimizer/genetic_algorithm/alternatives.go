@@ -0,0 +1,101 @@
+package genetic_algorithm
+
+// alternativesArchiveFactor limits the archive of diverse solutions kept during a run to this many times the number
+// of requested alternatives.
+const alternativesArchiveFactor = 10
+
+// Alternatives configures returning several distinct itineraries. Solutions are compared by the Jaccard distance
+// of their sets of visited POIs, or of their sets of transitions between consecutive visits if Ordering is set, so
+// that the same POIs visited in another order also count as a different itinerary. An alternative is accepted only
+// if its distance to every better one is at least MinimumDistance.
+type Alternatives struct {
+	Count           int
+	MinimumDistance float64
+	Ordering        bool
+}
+
+// Alternative is one of the itineraries returned by Alternatives with the terms of its objective.
+type Alternative struct {
+	Itinerary ApiItinerary       `json:"itinerary"`
+	Objective ObjectiveBreakdown `json:"objective"`
+}
+
+// SetAlternatives enables keeping an archive of good and diverse solutions during Solve, nil disables it.
+func (ga *GeneticAlgorithm) SetAlternatives(alternatives *Alternatives) {
+	ga.alternatives = alternatives
+}
+
+// Alternatives returns up to Count best distinct itineraries from the final population and the archive of the last
+// run of Solve, starting from the best one.
+func (ga *GeneticAlgorithm) Alternatives() []Alternative {
+	if ga.alternatives == nil {
+		return nil
+	}
+	candidates := mergeSolutions(ga.archive, ga.population)
+	sortSolutions(candidates)
+	selected := ga.alternatives.selectDistinct(candidates, ga.alternatives.Count)
+
+	alternatives := make([]Alternative, 0, len(selected))
+	for i := range selected {
+		alternatives = append(alternatives, Alternative{
+			Itinerary: ga.result(selected[i].itinerary),
+			Objective: ga.objectiveBreakdown(&selected[i].itinerary),
+		})
+	}
+	return alternatives
+}
+
+// updateArchive adds the best solutions of the population to the archive, keeping it diverse and limited in size.
+func (ga *GeneticAlgorithm) updateArchive() {
+	size := alternativesArchiveFactor * ga.alternatives.Count
+	candidates := mergeSolutions(ga.archive, ga.population)
+	sortSolutions(candidates)
+	ga.archive = ga.alternatives.selectDistinct(candidates, size)
+}
+
+// selectDistinct greedily takes solutions in the given order which are far enough from the ones already taken.
+func (a *Alternatives) selectDistinct(solutions []solution, count int) []solution {
+	selected := make([]solution, 0, count)
+	sets := keySets(solutions, a.itineraryKeys)
+	selectedSets := make([]keySet, 0, count)
+	for i, sol := range solutions {
+		if len(selected) >= count {
+			break
+		}
+		distinct := true
+		for _, other := range selectedSets {
+			distance := sets[i].distance(other)
+			if distance == 0 || distance < a.MinimumDistance {
+				distinct = false
+				break
+			}
+		}
+		if distinct {
+			selected = append(selected, sol)
+			selectedSets = append(selectedSets, sets[i])
+		}
+	}
+	return selected
+}
+
+// poiTransition is a pair of consecutive POIs of a day, from is nil for the first visit of a day.
+type poiTransition struct {
+	from *POI
+	to   *POI
+}
+
+// itineraryKeys returns the visited POIs, or the transitions between them if Ordering is set.
+func (a *Alternatives) itineraryKeys(itinerary *Itinerary) []interface{} {
+	if !a.Ordering {
+		return visitedPois(itinerary)
+	}
+	keys := make([]interface{}, 0)
+	for _, day := range itinerary.Days {
+		var previous *POI
+		for _, visit := range day.Visits {
+			keys = append(keys, poiTransition{from: previous, to: visit.Poi})
+			previous = visit.Poi
+		}
+	}
+	return keys
+}
@@ -0,0 +1,155 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+const minimumPheromone = 0.01
+const maximumPheromone = 10.0
+
+// AntColonyOptimization builds itineraries day by day. Every ant appends to the current day POIs which still fit
+// with a random duration between minimumVisitDuration and defaultVisitDuration, chosen with probability
+// proportional to pheromone^alpha * heuristic^beta. The heuristic prefers POIs with high satisfaction which can be
+// reached with little travel and waiting. After every iteration pheromone evaporates and is deposited on the
+// transitions of the best itinerary of the iteration and the best one so far.
+type AntColonyOptimization struct {
+	problem
+	ants        int
+	iterations  int
+	alpha       float64
+	beta        float64
+	evaporation float64
+}
+
+func CreateAntColonyOptimization(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) *AntColonyOptimization {
+	return &AntColonyOptimization{
+		problem: createProblem(dayBeginHour, dayEndHour, daysList, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier),
+		ants:        20,
+		iterations:  100,
+		alpha:       1.0,
+		beta:        2.0,
+		evaporation: 0.1,
+	}
+}
+
+// SetColony sets the number of ants and iterations, 20 and 100 by default. Values which are not positive keep the
+// current setting.
+func (aco *AntColonyOptimization) SetColony(ants int, iterations int) {
+	if ants > 0 {
+		aco.ants = ants
+	}
+	if iterations > 0 {
+		aco.iterations = iterations
+	}
+}
+
+// SetPheromone sets the weight of pheromone (1 by default) and of the heuristic (2 by default) and the part of
+// pheromone which evaporates after every iteration (0.1 by default).
+func (aco *AntColonyOptimization) SetPheromone(alpha float64, beta float64, evaporation float64) {
+	aco.alpha = alpha
+	aco.beta = beta
+	aco.evaporation = evaporation
+}
+
+func (aco *AntColonyOptimization) Solve(ctx context.Context) (ApiItinerary, RunReport) {
+	aco.startRun()
+
+	poiIndex := make(map[*POI]int, len(aco.poiList))
+	for i, poi := range aco.poiList {
+		poiIndex[poi] = i
+	}
+	// the last row holds pheromone of transitions from the beginning of a day
+	pheromone := make([][]float64, len(aco.poiList)+1)
+	for i := range pheromone {
+		pheromone[i] = make([]float64, len(aco.poiList))
+		for j := range pheromone[i] {
+			pheromone[i][j] = 1.0
+		}
+	}
+
+	best := solution{itinerary: decodeItinerary(nil, nil, aco.dayBeginHour, aco.dayEndHour, aco.daysList)}
+	best.objectiveValue, _ = aco.evaluateItinerary(&best.itinerary)
+	for iteration := 0; iteration < aco.iterations && ctx.Err() == nil; iteration++ {
+		var iterationBest solution
+		for ant := 0; ant < aco.ants; ant++ {
+			candidate := solution{itinerary: aco.constructItinerary(pheromone, poiIndex)}
+			candidate.objectiveValue, _ = aco.evaluateItinerary(&candidate.itinerary)
+			if ant == 0 || candidate.objectiveValue > iterationBest.objectiveValue {
+				iterationBest = candidate
+			}
+		}
+		aco.recordOperator("construction", "ant colony", iterationBest.objectiveValue > best.objectiveValue)
+		if iterationBest.objectiveValue > best.objectiveValue {
+			best = iterationBest
+		}
+
+		for i := range pheromone {
+			for j := range pheromone[i] {
+				pheromone[i][j] = math.Max(minimumPheromone, (1.0-aco.evaporation)*pheromone[i][j])
+			}
+		}
+		for _, sol := range []solution{iterationBest, best} {
+			for _, day := range sol.itinerary.Days {
+				previous := len(aco.poiList)
+				for _, visit := range day.Visits {
+					current := poiIndex[visit.Poi]
+					pheromone[previous][current] = math.Min(maximumPheromone, pheromone[previous][current]+1.0)
+					previous = current
+				}
+			}
+		}
+	}
+	aco.stopReason = iterationsStopReason(ctx)
+	return aco.result(best.itinerary), aco.Report()
+}
+
+func (aco *AntColonyOptimization) constructItinerary(pheromone [][]float64, poiIndex map[*POI]int) Itinerary {
+	itinerary := Itinerary{
+		Days:         make([]Day, len(aco.daysList)),
+		DayBeginHour: aco.dayBeginHour,
+		DayEndHour:   aco.dayEndHour,
+	}
+	used := make([]bool, len(aco.poiList))
+	for dayNumber, dayName := range aco.daysList {
+		day := Day{Visits: make([]Visit, 0), DayNumber: dayNumber, DayName: dayName, breaks: aco.breaks}
+		previous := len(aco.poiList)
+		for {
+			candidates := make([]Visit, 0)
+			weights := make([]float64, 0)
+			totalWeight := 0.0
+			for i, poi := range aco.poiList {
+				if used[i] {
+					continue
+				}
+				duration := minimumVisitDuration + aco.random.Intn(defaultVisitDuration-minimumVisitDuration+1)
+				visit, ok := scheduleNextVisit(&day, poi, duration, aco.dayBeginHour, aco.dayEndHour)
+				if !ok {
+					continue
+				}
+				// minutes spent on getting to the POI and waiting for its opening
+				delay := float64(calculateDuration(aco.dayBeginHour, visit.StartVisit))
+				if len(day.Visits) > 0 {
+					delay = float64(calculateDuration(day.Visits[len(day.Visits)-1].EndVisit, visit.StartVisit))
+				}
+				heuristic := (poi.Satisfaction + 0.1) / (1.0 + delay)
+				weight := math.Pow(pheromone[previous][i], aco.alpha) * math.Pow(heuristic, aco.beta)
+				candidates = append(candidates, visit)
+				weights = append(weights, weight)
+				totalWeight += weight
+			}
+			if len(candidates) == 0 {
+				break
+			}
+			visit := candidates[pickByWeight(weights, totalWeight, aco.random)]
+			day.Visits = append(day.Visits, visit)
+			previous = poiIndex[visit.Poi]
+			used[previous] = true
+		}
+		itinerary.Days[dayNumber] = day
+	}
+	return itinerary
+}
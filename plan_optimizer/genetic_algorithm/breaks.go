@@ -0,0 +1,233 @@
+package genetic_algorithm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Break is a rest needed on every day of the trip, like lunch. It lasts from MinDuration to MaxDuration minutes
+// between WindowStart and WindowEnd. It is taken in idle time of the day or during a visit of a restaurant or a
+// café which lasts at least MinDuration minutes within the window.
+type Break struct {
+	Name        string
+	MinDuration int
+	MaxDuration int
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
+
+// types of the visits of a returned itinerary
+const (
+	visitType = "visit"
+	breakType = "break"
+)
+
+// mealCategories are the categories of POIs where a break can be taken, together with their subcategories.
+var mealCategories = []string{"catering.restaurant", "catering.cafe"}
+
+// SetBreaks makes every day of the next runs keep the breaks. Visits are delayed when a break would not fit in
+// the day otherwise and days without one of their breaks fail the BreaksTaken constraint. Breaks taken in idle time
+// are returned as visits of type "break" at the place where they are taken, visits of restaurants and cafés
+// name the break taken during them. Breaks are taken one after another in the order of their windows. nil
+// disables them. It fails if a break does not fit in its window within the day.
+func (p *problem) SetBreaks(breaks []Break) error {
+	for _, b := range breaks {
+		if b.MinDuration <= 0 || b.MaxDuration < b.MinDuration {
+			return fmt.Errorf("break %q has to last at least a minute and at most its maximum duration", b.Name)
+		}
+		if calculateDuration(b.WindowStart, b.WindowEnd) < b.MinDuration {
+			return fmt.Errorf("break %q does not fit in its window", b.Name)
+		}
+		if calculateDuration(maxHour(b.WindowStart, p.dayBeginHour), minHour(b.WindowEnd, p.dayEndHour)) <
+			b.MinDuration {
+			return fmt.Errorf("break %q does not fit in the day between %s and %s", b.Name,
+				p.dayBeginHour.Format("15:04"), p.dayEndHour.Format("15:04"))
+		}
+	}
+	p.breaks = nil
+	if len(breaks) > 0 {
+		p.breaks = append([]Break(nil), breaks...)
+		sort.SliceStable(p.breaks, func(i, j int) bool {
+			return p.breaks[i].WindowStart.Before(p.breaks[j].WindowStart)
+		})
+	}
+	return nil
+}
+
+// scheduleBreaks makes every day of the itinerary keep the breaks set by SetBreaks and schedules it again.
+func (p *problem) scheduleBreaks(itinerary *Itinerary) {
+	if p.breaks == nil {
+		return
+	}
+	for i := range itinerary.Days {
+		itinerary.Days[i].breaks = p.breaks
+		repairDaySchedule(&itinerary.Days[i], itinerary.DayBeginHour, itinerary.DayEndHour)
+	}
+}
+
+func servesMeals(poi *POI) bool {
+	for _, category := range poi.Categories {
+		for _, meal := range mealCategories {
+			if category == meal || strings.HasPrefix(category, meal+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// breakSlot is the time of a break taken before the visit with the given index, or during it if meal is set. The
+// index equal to the number of visits stands for the time after the last visit. taken is false if the break does
+// not fit in the day.
+type breakSlot struct {
+	start time.Time
+	end   time.Time
+	visit int
+	meal  bool
+	taken bool
+}
+
+// breakSlots finds the earliest slot of every break of the day, each one after the slot of the previous break.
+// Idle time before a visit begins after travelling to it, like waiting for the opening of a POI.
+func breakSlots(day Day, dayBeginHour, dayEndHour time.Time) []breakSlot {
+	slots := make([]breakSlot, len(day.breaks))
+	after := dayBeginHour
+	for k, b := range day.breaks {
+		for i := 0; i <= len(day.Visits) && !slots[k].taken; i++ {
+			idleStart, idleEnd := dayBeginHour, dayEndHour
+			if i > 0 {
+				previous := day.Visits[i-1]
+				idleStart = previous.EndVisit
+				if i < len(day.Visits) {
+					idleStart = addMinutes(previous.EndVisit, transport(previous.Poi, day.Visits[i].Poi))
+				}
+			}
+			if i < len(day.Visits) {
+				idleEnd = day.Visits[i].StartVisit
+			}
+			slots[k] = b.slot(maxHour(idleStart, after), idleEnd, i, false)
+			if !slots[k].taken && i < len(day.Visits) && servesMeals(day.Visits[i].Poi) {
+				slots[k] = b.slot(maxHour(day.Visits[i].StartVisit, after), day.Visits[i].EndVisit, i, true)
+			}
+		}
+		if slots[k].taken {
+			after = slots[k].end
+		}
+	}
+	return slots
+}
+
+// slot places the break at the beginning of the time from start to end which is within its window.
+func (b Break) slot(start, end time.Time, visit int, meal bool) breakSlot {
+	start = maxHour(start, b.WindowStart)
+	end = minHour(end, b.WindowEnd)
+	if calculateDuration(start, end) < b.MinDuration {
+		return breakSlot{}
+	}
+	return breakSlot{
+		start: start,
+		end:   minHour(end, addMinutes(start, b.MaxDuration)),
+		visit: visit,
+		meal:  meal,
+		taken: true,
+	}
+}
+
+// makeRoomForBreaks delays the visit scheduled after the last visit of the day when one of the breaks would not
+// fit in the day with it, so that the break is taken after arriving at the POI. Breaks which cannot be taken any
+// more are skipped. ok is false if the delayed visit cannot last minimumVisitDuration minutes or if it is too late
+// for a break which the day could still take without the visit.
+func makeRoomForBreaks(day *Day, visit Visit, preferredDuration int, arrival, dayBeginHour,
+	dayEndHour time.Time) (Visit, bool) {
+	var current []breakSlot
+	if len(day.breaks) > 0 {
+		current = breakSlots(*day, dayBeginHour, dayEndHour)
+	}
+	for k, b := range day.breaks {
+		candidate := *day
+		candidate.Visits = append(day.Visits[:len(day.Visits):len(day.Visits)], visit)
+		slots := breakSlots(candidate, dayBeginHour, dayEndHour)
+		if slots[k].taken {
+			continue
+		}
+		start := maxHour(arrival, b.WindowStart)
+		if k > 0 && slots[k-1].taken {
+			start = maxHour(start, slots[k-1].end)
+		}
+		readyAt := addMinutes(start, b.MinDuration)
+		if readyAt.After(b.WindowEnd) {
+			if current[k].taken {
+				return Visit{}, false
+			}
+			continue
+		}
+		var ok bool
+		if visit, ok = scheduleVisit(day.DayName, visit.Poi, preferredDuration, readyAt, dayBeginHour,
+			dayEndHour); !ok {
+			return Visit{}, false
+		}
+	}
+	return visit, true
+}
+
+// apiBreaks returns the breaks of the day taken in idle time by the index of the visit they precede and the names
+// of the breaks taken during visits by the index of the visit. Days without visits do not need planned breaks.
+func apiBreaks(day *Day, dayBeginHour, dayEndHour time.Time) (map[int][]ApiVisit, map[int]string) {
+	idle := make(map[int][]ApiVisit)
+	meals := make(map[int]string)
+	if len(day.Visits) == 0 {
+		return idle, meals
+	}
+	for k, slot := range breakSlots(*day, dayBeginHour, dayEndHour) {
+		if !slot.taken {
+			continue
+		}
+		if slot.meal {
+			meals[slot.visit] = day.breaks[k].Name
+			continue
+		}
+		place := day.Visits[len(day.Visits)-1].Poi
+		if slot.visit < len(day.Visits) {
+			place = day.Visits[slot.visit].Poi
+		}
+		idle[slot.visit] = append(idle[slot.visit], ApiVisit{
+			Poi:           ApiPOI{Name: day.breaks[k].Name, Lat: place.Lat, Lon: place.Lon},
+			StartVisit:    slot.start.Format("15:04"),
+			EndVisit:      slot.end.Format("15:04"),
+			VisitDuration: calculateDuration(slot.start, slot.end),
+			Type:          breakType,
+		})
+	}
+	return idle, meals
+}
+
+// BreaksTaken fails if a day with visits misses one of its breaks.
+type BreaksTaken struct {
+	next Constraint
+}
+
+func (c *BreaksTaken) execute(itinerary *Itinerary, failed *ConstraintsCount) {
+	failedConstraint := false
+	for _, day := range itinerary.Days {
+		if len(day.Visits) == 0 {
+			continue
+		}
+		for _, slot := range breakSlots(day, itinerary.DayBeginHour, itinerary.DayEndHour) {
+			if !slot.taken {
+				failedConstraint = true
+			}
+		}
+	}
+	if failedConstraint {
+		failed.add("BreaksTaken")
+	}
+	if c.next != nil {
+		c.next.execute(itinerary, failed)
+	}
+}
+
+func (c *BreaksTaken) setNext(next Constraint) {
+	c.next = next
+}
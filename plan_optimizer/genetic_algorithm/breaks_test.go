@@ -0,0 +1,136 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var lunch = Break{Name: "lunch", MinDuration: 45, MaxDuration: 60, WindowStart: clock(12 * 60),
+	WindowEnd: clock(14 * 60)}
+
+// breakTestPois are POIs open the whole day, none of them serving meals.
+func breakTestPois(n int) []*POI {
+	pois := randomPois(rand.New(rand.NewSource(15)), n)
+	for _, poi := range pois {
+		for _, day := range testDays {
+			poi.OpenHour[day], poi.CloseHour[day] = clock(8*60), clock(20*60)
+		}
+	}
+	return pois
+}
+
+// decodeDayWithLunch schedules the POIs for the given minutes on the first day, keeping the lunch break.
+func decodeDayWithLunch(pois []*POI, durations ...int) Day {
+	preferred := make(map[*POI]int)
+	for i, duration := range durations {
+		preferred[pois[i]] = duration
+	}
+	template := Day{DayNumber: 0, DayName: testDays[0], breaks: []Break{lunch}}
+	return decodeDay(pois, preferred, template, clock(9*60), clock(19*60))
+}
+
+func breaksTaken(day Day) bool {
+	failed := ConstraintsCount{}
+	itinerary := Itinerary{Days: []Day{day}, DayBeginHour: clock(9 * 60), DayEndHour: clock(19 * 60)}
+	(&BreaksTaken{}).execute(&itinerary, &failed)
+	return failed.failedConstraints == 0
+}
+
+func TestBreakInIdleGap(t *testing.T) {
+	pois := breakTestPois(2)
+	pois[1].OpenHour[testDays[0]] = clock(13*60 + 30)
+	day := decodeDayWithLunch(pois, 150, 0)
+	if len(day.Visits) != 2 || !day.Visits[1].StartVisit.Equal(clock(13*60+30)) {
+		t.Fatalf("visits %+v, expected the second one at its opening", day.Visits)
+	}
+
+	idle, meals := apiBreaks(&day, clock(9*60), clock(19*60))
+	breaks := idle[1]
+	if len(breaks) != 1 || len(meals) != 0 || breaks[0].Type != breakType || breaks[0].StartVisit != "12:00" ||
+		breaks[0].EndVisit != "13:00" {
+		t.Errorf("breaks %+v and meals %v, expected the longest lunch before the second visit", idle, meals)
+	}
+	if !breaksTaken(day) {
+		t.Errorf("lunch in the idle gap is not counted as taken")
+	}
+}
+
+func TestBreakDuringRestaurantVisit(t *testing.T) {
+	pois := breakTestPois(3)
+	pois[1].Categories = []string{"catering.restaurant.pizza"}
+	day := decodeDayWithLunch(pois, 180, 90, 180)
+	if len(day.Visits) != 3 {
+		t.Fatalf("visits %+v, expected all three", day.Visits)
+	}
+	arrival := addMinutes(day.Visits[0].EndVisit, transport(pois[0], pois[1]))
+	if !day.Visits[1].StartVisit.Equal(arrival) {
+		t.Errorf("restaurant visit is delayed to %s instead of %s", day.Visits[1].StartVisit.Format("15:04"),
+			arrival.Format("15:04"))
+	}
+
+	idle, meals := apiBreaks(&day, clock(9*60), clock(19*60))
+	if len(idle) != 0 || meals[1] != lunch.Name {
+		t.Errorf("breaks %+v and meals %v, expected lunch during the restaurant visit", idle, meals)
+	}
+	if !breaksTaken(day) {
+		t.Errorf("lunch during the restaurant visit is not counted as taken")
+	}
+}
+
+func TestVisitDelayedForBreak(t *testing.T) {
+	pois := breakTestPois(2)
+	day := decodeDayWithLunch(pois, 180, 180)
+	if len(day.Visits) != 2 {
+		t.Fatalf("visits %+v, expected both", day.Visits)
+	}
+	arrival := addMinutes(day.Visits[0].EndVisit, transport(pois[0], pois[1]))
+	if delay := calculateDuration(arrival, day.Visits[1].StartVisit); delay < lunch.MinDuration {
+		t.Errorf("second visit is delayed by %d minutes, lunch needs %d", delay, lunch.MinDuration)
+	}
+	if !breaksTaken(day) {
+		t.Errorf("lunch before the delayed visit is not counted as taken")
+	}
+}
+
+func TestBreaksTakenFailsWithoutBreak(t *testing.T) {
+	pois := breakTestPois(2)
+	// the visits leave ten minutes between them during the whole window of the lunch
+	first := Visit{Poi: pois[0], StartVisit: clock(9 * 60), EndVisit: clock(12*60 + 30), VisitDuration: 210}
+	start := addMinutes(first.EndVisit, transport(pois[0], pois[1])+10)
+	second := Visit{Poi: pois[1], StartVisit: start, EndVisit: clock(15 * 60), VisitDuration: calculateDuration(start,
+		clock(15*60))}
+	day := Day{DayName: testDays[0], Visits: []Visit{first, second}, breaks: []Break{lunch}}
+	if breaksTaken(day) {
+		t.Errorf("day without time for lunch passes BreaksTaken")
+	}
+
+	if !breaksTaken(day.withoutVisits()) {
+		t.Errorf("day without visits needs a lunch break")
+	}
+	day.breaks = nil
+	if !breaksTaken(day) {
+		t.Errorf("day without breaks fails BreaksTaken")
+	}
+}
+
+func TestSetBreaksRejectsWindowOutsideDay(t *testing.T) {
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, test := range []struct {
+		start, end int
+		valid      bool
+	}{
+		{12 * 60, 14 * 60, true},
+		{18 * 60, 20 * 60, true},
+		{18*60 + 30, 20 * 60, false},
+		{20 * 60, 21 * 60, false},
+		{7 * 60, 8*60 + 30, false},
+	} {
+		b := lunch
+		b.WindowStart, b.WindowEnd = clock(test.start), clock(test.end)
+		err := geneticAlgorithm.SetBreaks([]Break{b})
+		if (err == nil) != test.valid {
+			t.Errorf("window from %s to %s: error %v", b.WindowStart.Format("15:04"), b.WindowEnd.Format("15:04"),
+				err)
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package genetic_algorithm
 
-import "fmt"
-
 type ConstraintsCount struct {
 	failedConstraints int
+	names             []string
+}
+
+// add records a failure of the named constraint.
+func (c *ConstraintsCount) add(name string) {
+	c.failedConstraints += 1
+	c.names = append(c.names, name)
 }
 
 type Constraint interface {
@@ -19,6 +24,9 @@ func (v *VisitsWithinDayLimits) execute(itinerary *Itinerary, failed *Constraint
 	failedConstraint := false
 	for _, day := range itinerary.Days {
 		dayLen := len(day.Visits)
+		if dayLen == 0 {
+			continue
+		}
 		if day.Visits[0].StartVisit.Before(itinerary.DayBeginHour) ||
 			day.Visits[dayLen-1].EndVisit.After(itinerary.DayEndHour) {
 			failedConstraint = true
@@ -26,8 +34,7 @@ func (v *VisitsWithinDayLimits) execute(itinerary *Itinerary, failed *Constraint
 		}
 	}
 	if failedConstraint {
-		failed.failedConstraints += 1
-		fmt.Println("VisitsWithinDayLimits")
+		failed.add("VisitsWithinDayLimits")
 	}
 	if v.next != nil {
 		v.next.execute(itinerary, failed)
@@ -55,8 +62,7 @@ func (t *TimeDifferenceBetweenPoints) execute(itinerary *Itinerary, failed *Cons
 		}
 	}
 	if failedConstraint {
-		failed.failedConstraints += 1
-		fmt.Println("TimeDifferenceBetweenPoints")
+		failed.add("TimeDifferenceBetweenPoints")
 	}
 	if t.next != nil {
 		t.next.execute(itinerary, failed)
@@ -82,8 +88,7 @@ func (p *PoiOpenedDuringVisit) execute(itinerary *Itinerary, failed *Constraints
 		}
 	}
 	if failedConstraint {
-		failed.failedConstraints += 1
-		fmt.Println("PoiOpenedDuringVisit")
+		failed.add("PoiOpenedDuringVisit")
 	}
 	if p.next != nil {
 		p.next.execute(itinerary, failed)
@@ -102,15 +107,14 @@ func (m *MinimumTimeInPoi) execute(itinerary *Itinerary, failed *ConstraintsCoun
 	failedConstraint := false
 	for _, day := range itinerary.Days {
 		for _, visit := range day.Visits {
-			if calculateDuration(visit.StartVisit, visit.EndVisit) < 60 {
+			if calculateDuration(visit.StartVisit, visit.EndVisit) < minimumVisitDuration {
 				failedConstraint = true
 				break
 			}
 		}
 	}
 	if failedConstraint {
-		failed.failedConstraints += 1
-		fmt.Println("MinimumTimeInPoi")
+		failed.add("MinimumTimeInPoi")
 	}
 	if m.next != nil {
 		m.next.execute(itinerary, failed)
@@ -138,8 +142,7 @@ func (o *OriginalPoi) execute(itinerary *Itinerary, failed *ConstraintsCount) {
 		}
 	}
 	if failedConstraint {
-		failed.failedConstraints += 1
-		fmt.Println("OriginalPoi")
+		failed.add("OriginalPoi")
 	}
 	if o.next != nil {
 		o.next.execute(itinerary, failed)
@@ -0,0 +1,274 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// ConstructionHeuristic builds an itinerary from scratch, e.g. to seed the initial population. Every step picks at
+// random one of the candidates best POIs, so that one heuristic creates many different itineraries; with one
+// candidate it is deterministic. Visits last defaultVisitDuration minutes when opening hours allow.
+type ConstructionHeuristic interface {
+	construct(p *problem, candidates int, random *rand.Rand) Itinerary
+	name() string
+}
+
+// GreedyConstruction fills the days one after another, always appending the POI with the best ratio of its value
+// in the objective function to the time it takes, counting travel and waiting for the opening.
+type GreedyConstruction struct{}
+
+func (GreedyConstruction) name() string { return "greedy" }
+
+func (GreedyConstruction) construct(p *problem, candidates int, random *rand.Rand) Itinerary {
+	return p.constructByAppending(candidates, random, func(visit Visit, delay int) float64 {
+		return p.visitValue(visit) / float64(delay+visit.VisitDuration)
+	})
+}
+
+// NearestNeighbourConstruction fills the days one after another, always appending the POI where the next visit can
+// start earliest, counting travel and waiting for the opening. Ties are broken by satisfaction.
+type NearestNeighbourConstruction struct{}
+
+func (NearestNeighbourConstruction) name() string { return "nearest-neighbour" }
+
+func (NearestNeighbourConstruction) construct(p *problem, candidates int, random *rand.Rand) Itinerary {
+	return p.constructByAppending(candidates, random, func(visit Visit, delay int) float64 {
+		// delays are whole minutes and satisfaction is mapped below half a minute, so it only breaks ties
+		satisfaction := visit.Poi.Satisfaction
+		return -float64(delay) + 0.5*satisfaction/(1.0+math.Abs(satisfaction))
+	})
+}
+
+// RegretInsertion inserts POIs at the best position of any day. For every POI it compares the gain of inserting
+// it into the best day with the gain in the second best day and first inserts the POI with the largest
+// difference, which would lose the most if its best day filled up.
+type RegretInsertion struct{}
+
+func (RegretInsertion) name() string { return "regret" }
+
+// AllConstructionHeuristics returns every available construction heuristic.
+func AllConstructionHeuristics() []ConstructionHeuristic {
+	return []ConstructionHeuristic{GreedyConstruction{}, NearestNeighbourConstruction{}, RegretInsertion{}}
+}
+
+// visitValue is the contribution of a visit to the objective function.
+func (p *problem) visitValue(visit Visit) float64 {
+	return p.satisfactionMultiplier*float64(visit.VisitDuration)/(24.0*60.0)*visit.Poi.Satisfaction + p.poiMultiplier
+}
+
+func (p *problem) dayValue(day Day) float64 {
+	value := 0.0
+	for _, visit := range day.Visits {
+		value += p.visitValue(visit)
+	}
+	return value
+}
+
+func (p *problem) emptyItinerary() Itinerary {
+	itinerary := decodeItinerary(nil, nil, p.dayBeginHour, p.dayEndHour, p.daysList)
+	p.scheduleBreaks(&itinerary)
+	return itinerary
+}
+
+// scoredCandidate is a possible step of a construction heuristic.
+type scoredCandidate struct {
+	index int
+	score float64
+}
+
+// pickCandidate returns the index of one of the count best scored candidates chosen at random.
+func pickCandidate(scored []scoredCandidate, count int, random *rand.Rand) int {
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+	if count < 1 {
+		count = 1
+	}
+	if count > len(scored) {
+		count = len(scored)
+	}
+	return scored[random.Intn(count)].index
+}
+
+// constructByAppending fills the days one after another with the best scored POIs which can still be visited.
+// score gets the scheduled visit and the minutes between the end of the previous visit, or the beginning of the
+// day, and the start of the visit.
+func (p *problem) constructByAppending(candidates int, random *rand.Rand,
+	score func(visit Visit, delay int) float64) Itinerary {
+	itinerary := p.emptyItinerary()
+	used := make(map[*POI]bool)
+	for dayId := range itinerary.Days {
+		day := &itinerary.Days[dayId]
+		for {
+			visits := make([]Visit, 0)
+			scored := make([]scoredCandidate, 0)
+			for _, poi := range p.poiList {
+				if used[poi] {
+					continue
+				}
+				visit, ok := scheduleNextVisit(day, poi, defaultVisitDuration, p.dayBeginHour, p.dayEndHour)
+				if !ok {
+					continue
+				}
+				delay := calculateDuration(p.dayBeginHour, visit.StartVisit)
+				if len(day.Visits) > 0 {
+					delay = calculateDuration(day.Visits[len(day.Visits)-1].EndVisit, visit.StartVisit)
+				}
+				scored = append(scored, scoredCandidate{index: len(visits), score: score(visit, delay)})
+				visits = append(visits, visit)
+			}
+			if len(scored) == 0 {
+				break
+			}
+			visit := visits[pickCandidate(scored, candidates, random)]
+			day.Visits = append(day.Visits, visit)
+			used[visit.Poi] = true
+		}
+	}
+	return itinerary
+}
+
+// insertionOption is the best insertion of a POI into one day.
+type insertionOption struct {
+	day  Day
+	gain float64
+	ok   bool
+}
+
+// bestDayInsertion finds the position in the day where inserting the POI increases the value of the day the most.
+func (p *problem) bestDayInsertion(day Day, poi *POI) insertionOption {
+	best := insertionOption{}
+	if calculateDuration(maxHour(p.dayBeginHour, poi.OpenHour[day.DayName]),
+		minHour(p.dayEndHour, poi.CloseHour[day.DayName])) < minimumVisitDuration {
+		return best
+	}
+	currentValue := p.dayValue(day)
+	for position := 0; position <= len(day.Visits); position++ {
+		visits := make([]Visit, 0, len(day.Visits)+1)
+		visits = append(visits, day.Visits[:position]...)
+		visits = append(visits, Visit{Poi: poi, VisitDuration: defaultVisitDuration})
+		visits = append(visits, day.Visits[position:]...)
+		candidate := day.withoutVisits()
+		if !tryDaySchedule(&candidate, visits, p.dayBeginHour, p.dayEndHour) {
+			continue
+		}
+		gain := p.dayValue(candidate) - currentValue
+		if gain > 0 && (!best.ok || gain > best.gain) {
+			best = insertionOption{day: candidate, gain: gain, ok: true}
+		}
+	}
+	return best
+}
+
+func (RegretInsertion) construct(p *problem, candidates int, random *rand.Rand) Itinerary {
+	itinerary := p.emptyItinerary()
+	unused := append([]*POI(nil), p.poiList...)
+	// best insertion of every unused POI into every day, only the changed day is evaluated again after a step
+	options := make([][]insertionOption, len(unused))
+	for i, poi := range unused {
+		options[i] = make([]insertionOption, len(itinerary.Days))
+		for dayId, day := range itinerary.Days {
+			options[i][dayId] = p.bestDayInsertion(day, poi)
+		}
+	}
+
+	for len(unused) > 0 {
+		scored := make([]scoredCandidate, 0, len(unused))
+		for i := range unused {
+			first, second := -1.0, 0.0
+			for _, option := range options[i] {
+				if !option.ok {
+					continue
+				}
+				if option.gain > first {
+					first, second = option.gain, first
+				} else if option.gain > second {
+					second = option.gain
+				}
+			}
+			if first < 0 {
+				continue
+			}
+			if second < 0 {
+				second = 0
+			}
+			scored = append(scored, scoredCandidate{index: i, score: first - second + first*1e-6})
+		}
+		if len(scored) == 0 {
+			break
+		}
+		chosen := pickCandidate(scored, candidates, random)
+		bestDayId := -1
+		for dayId, option := range options[chosen] {
+			if option.ok && (bestDayId < 0 || option.gain > options[chosen][bestDayId].gain) {
+				bestDayId = dayId
+			}
+		}
+		itinerary.Days[bestDayId] = options[chosen][bestDayId].day
+
+		unused = append(unused[:chosen], unused[chosen+1:]...)
+		options = append(options[:chosen], options[chosen+1:]...)
+		for i, poi := range unused {
+			options[i][bestDayId] = p.bestDayInsertion(itinerary.Days[bestDayId], poi)
+		}
+	}
+	return itinerary
+}
+
+// Seeding configures building a part of the initial population with construction heuristics instead of random
+// itineraries. Fraction of the population between 0 and 1 is constructed, using Heuristics in turn, all of them
+// by default. The first itinerary of every heuristic is deterministic, the next ones choose every step among
+// Candidates best options, 3 by default.
+type Seeding struct {
+	Fraction   float64
+	Heuristics []ConstructionHeuristic
+	Candidates int
+}
+
+// SetSeeding enables seeding of the initial population with construction heuristics, nil disables it.
+func (ga *GeneticAlgorithm) SetSeeding(seeding *Seeding) {
+	ga.seeding = seeding
+}
+
+// seededCount is the number of solutions of an initial population of the given size which come from the warm start
+// or construction heuristics.
+func (ga *GeneticAlgorithm) seededCount(populationSize int) int {
+	count := 0
+	if ga.warmStart != nil {
+		count += 1 + ga.warmStart.variants
+	}
+	if ga.seeding != nil && ga.seeding.Fraction > 0 {
+		count += int(ga.seeding.Fraction*float64(populationSize) + 0.5)
+	}
+	if count > populationSize {
+		count = populationSize
+	}
+	return count
+}
+
+// constructSeed builds the i-th seeded solution of the initial population: the warm start itinerary, its variants
+// and then itineraries of construction heuristics.
+func (ga *GeneticAlgorithm) constructSeed(i int, random *rand.Rand) Itinerary {
+	if ga.warmStart != nil {
+		if i == 0 {
+			return copyItinerary(&ga.warmStart.itinerary)
+		}
+		if i <= ga.warmStart.variants {
+			return ga.warmStart.variant(ga.poiList, random)
+		}
+		i -= 1 + ga.warmStart.variants
+	}
+	heuristics := ga.seeding.Heuristics
+	if len(heuristics) == 0 {
+		heuristics = AllConstructionHeuristics()
+	}
+	candidates := 1
+	if i >= len(heuristics) {
+		candidates = ga.seeding.Candidates
+		if candidates <= 0 {
+			candidates = 3
+		}
+	}
+	return heuristics[i%len(heuristics)].construct(&ga.problem, candidates, random)
+}
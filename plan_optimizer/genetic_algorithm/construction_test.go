@@ -0,0 +1,108 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+)
+
+func constructionTestProblem(pois []*POI) *GeneticAlgorithm {
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	return geneticAlgorithm
+}
+
+func TestConstructedItinerariesAreFeasible(t *testing.T) {
+	geneticAlgorithm := constructionTestProblem(randomPois(rand.New(rand.NewSource(12)), 40))
+	lunch := Break{Name: "lunch", MinDuration: 45, MaxDuration: 60, WindowStart: clock(12 * 60),
+		WindowEnd: clock(14 * 60)}
+	random := rand.New(rand.NewSource(1))
+
+	for _, breaks := range [][]Break{nil, {lunch}} {
+		if err := geneticAlgorithm.SetBreaks(breaks); err != nil {
+			t.Fatal(err)
+		}
+		for _, heuristic := range AllConstructionHeuristics() {
+			for _, candidates := range []int{1, 3} {
+				itinerary := heuristic.construct(&geneticAlgorithm.problem, candidates, random)
+				if _, failed := geneticAlgorithm.evaluateItinerary(&itinerary); failed > 0 {
+					t.Errorf("%s with %d candidates and %d breaks fails %d constraints", heuristic.name(),
+						candidates, len(breaks), failed)
+				}
+				if len(itinerary.Days[0].Visits) == 0 {
+					t.Errorf("%s with %d candidates visits nothing on the first day", heuristic.name(), candidates)
+				}
+			}
+		}
+	}
+}
+
+func TestNearestNeighbourComparesDelayFirst(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(13)), 3)
+	for _, poi := range pois {
+		for _, day := range testDays {
+			poi.OpenHour[day], poi.CloseHour[day] = clock(8*60), clock(20*60)
+		}
+	}
+	// the first POI is the nearest to the third one, the second one is further away but much more satisfying
+	pois[2].Lat, pois[2].Lon = 50.05, 19.93
+	pois[0].Lat, pois[0].Lon, pois[0].Satisfaction = 50.051, 19.93, 1
+	pois[1].Lat, pois[1].Lon, pois[1].Satisfaction = 50.07, 19.93, 10
+	geneticAlgorithm := constructionTestProblem(pois)
+	// the third POI opens first, so that every day starts there
+	for _, day := range testDays {
+		pois[0].OpenHour[day], pois[1].OpenHour[day] = clock(10*60), clock(10*60)
+	}
+	itinerary := NearestNeighbourConstruction{}.construct(&geneticAlgorithm.problem, 1, rand.New(rand.NewSource(1)))
+	if sequence := daySequence(itinerary.Days[0]); len(sequence) != 3 || sequence[0] != pois[2] ||
+		sequence[1] != pois[0] {
+		t.Errorf("satisfaction outweighs travel time, the first day is %v", sequence)
+	}
+
+	// with equal delays the more satisfying POI comes first
+	pois[1].Lat, pois[1].Lon = pois[0].Lat, pois[0].Lon
+	itinerary = NearestNeighbourConstruction{}.construct(&geneticAlgorithm.problem, 1, rand.New(rand.NewSource(1)))
+	if sequence := daySequence(itinerary.Days[0]); len(sequence) != 3 || sequence[1] != pois[1] {
+		t.Errorf("ties are not broken by satisfaction, the first day is %v", sequence)
+	}
+}
+
+// countingConstruction builds empty itineraries and counts them.
+type countingConstruction struct {
+	count *int32
+}
+
+func (countingConstruction) name() string { return "counting" }
+
+func (c countingConstruction) construct(p *problem, _ int, _ *rand.Rand) Itinerary {
+	atomic.AddInt32(c.count, 1)
+	return p.emptyItinerary()
+}
+
+func TestSeedingFraction(t *testing.T) {
+	geneticAlgorithm := constructionTestProblem(randomPois(rand.New(rand.NewSource(14)), 30))
+	for _, test := range []struct {
+		fraction float64
+		seeded   int
+	}{{0, 0}, {0.25, 10}, {0.31, 12}, {1, 40}, {2, 40}} {
+		var count int32
+		geneticAlgorithm.SetSeeding(&Seeding{Fraction: test.fraction,
+			Heuristics: []ConstructionHeuristic{countingConstruction{count: &count}}})
+		geneticAlgorithm.SetSeed(1)
+		geneticAlgorithm.startRun()
+		geneticAlgorithm.createInitialPopulation(40, geneticAlgorithm.seededCount(40))
+
+		empty := 0
+		for _, sol := range geneticAlgorithm.population {
+			if len(daySequence(sol.itinerary.Days[0]))+len(daySequence(sol.itinerary.Days[1])) == 0 {
+				empty++
+			}
+		}
+		if int(count) != test.seeded || empty != test.seeded {
+			t.Errorf("fraction %.2f: %d constructed and %d empty itineraries, expected %d", test.fraction, count,
+				empty, test.seeded)
+		}
+	}
+}
@@ -1,9 +1,28 @@
 package genetic_algorithm
 
-import (
-	"math/rand"
-	"time"
-)
+import "math/rand"
+
+// CrossoverOperator recombines two parent itineraries into two children.
+type CrossoverOperator interface {
+	name() string
+	crossover(parent1, parent2 *Itinerary, allPoiList []*POI, random *rand.Rand) (Itinerary, Itinerary)
+}
+
+// DayBoundaryCrossover is the one-point crossover at day boundaries implemented by CrossoverMultipleDays. One day
+// trips cannot be split, so their children are copies of the parents.
+type DayBoundaryCrossover struct{}
+
+func (DayBoundaryCrossover) name() string {
+	return "day"
+}
+
+func (DayBoundaryCrossover) crossover(parent1, parent2 *Itinerary, allPoiList []*POI,
+	random *rand.Rand) (Itinerary, Itinerary) {
+	if len(parent1.Days) < 2 {
+		return copyItinerary(parent1), copyItinerary(parent2)
+	}
+	return CrossoverMultipleDays(parent1, parent2, allPoiList, random)
+}
 
 type PoiToChangeTuple struct {
 	DayId   int
@@ -13,9 +32,11 @@ type PoiToChangeTuple struct {
 
 func createChildMultipleDays(source1, source2 *Itinerary, divisionIndex int) Itinerary {
 	child := Itinerary{
-		Days:         make([]Day, len(source1.Days)),
-		DayBeginHour: source1.DayBeginHour,
-		DayEndHour:   source1.DayEndHour,
+		Days:          make([]Day, len(source1.Days)),
+		DayBeginHour:  source1.DayBeginHour,
+		DayEndHour:    source1.DayEndHour,
+		Accommodation: source1.Accommodation,
+		locks:         source1.locks,
 	}
 
 	for i := 0; i < divisionIndex; i++ {
@@ -29,11 +50,27 @@ func createChildMultipleDays(source1, source2 *Itinerary, divisionIndex int) Iti
 	return child
 }
 
+func copyItinerary(original *Itinerary) Itinerary {
+	itineraryCopy := Itinerary{
+		Days:          make([]Day, len(original.Days)),
+		DayBeginHour:  original.DayBeginHour,
+		DayEndHour:    original.DayEndHour,
+		Accommodation: original.Accommodation,
+		locks:         original.locks,
+	}
+	for i, day := range original.Days {
+		itineraryCopy.Days[i] = copyDay(day)
+	}
+	return itineraryCopy
+}
+
 func copyDay(original Day) Day {
 	dayCopy := Day{
 		Visits:    make([]Visit, len(original.Visits)),
 		DayNumber: original.DayNumber,
 		DayName:   original.DayName,
+		breaks:    original.breaks,
+		fixed:     original.fixed,
 	}
 
 	for j := 0; j < len(original.Visits); j++ {
@@ -52,16 +89,19 @@ func copyVisit(original Visit) Visit {
 	}
 }
 
-func createChildren(itinerary1, itinerary2 *Itinerary) (Itinerary, Itinerary) {
-	divisionIndex := rand.Intn(len(itinerary1.Days)-1) + 1
+func createChildren(itinerary1, itinerary2 *Itinerary, random *rand.Rand) (Itinerary, Itinerary) {
+	if len(itinerary1.Days) < 2 {
+		return copyItinerary(itinerary1), copyItinerary(itinerary2)
+	}
+	divisionIndex := random.Intn(len(itinerary1.Days)-1) + 1
 	child1 := createChildMultipleDays(itinerary1, itinerary2, divisionIndex)
 	child2 := createChildMultipleDays(itinerary2, itinerary1, divisionIndex)
 	return child1, child2
 }
 
-func CrossoverMultipleDays(itinerary1, itinerary2 *Itinerary, allPoiList []*POI) (Itinerary, Itinerary) {
+func CrossoverMultipleDays(itinerary1, itinerary2 *Itinerary, allPoiList []*POI, random *rand.Rand) (Itinerary, Itinerary) {
 
-	child1, child2 := createChildren(itinerary1, itinerary2)
+	child1, child2 := createChildren(itinerary1, itinerary2, random)
 
 	var newSolutions = []Itinerary{child1, child2}
 	var newPoiToChange PoiToChangeTuple
@@ -84,9 +124,6 @@ func CrossoverMultipleDays(itinerary1, itinerary2 *Itinerary, allPoiList []*POI)
 
 		for changeId, change := range poiToChange {
 			updatedPoi = nil
-			newVisitStart := time.Time{}
-			newVisitEnd := time.Time{}
-			poiFit := false
 			availablePoi := make([]*POI, 0)
 			for _, poi := range allPoiList {
 				if !containsPoi(usedPoiList, poi) {
@@ -96,12 +133,15 @@ func CrossoverMultipleDays(itinerary1, itinerary2 *Itinerary, allPoiList []*POI)
 
 			dayId := change.DayId
 			visitId := change.VisitId
-			day := itinerary.Days[dayId]
+			day := &itinerary.Days[dayId]
 
+			// the new POI takes the place and the duration of the duplicate, it fits if the whole day can be
+			// scheduled again without dropping any visit
 			for len(availablePoi) > 0 {
-				newPoi, newPoiIndex := drawPoi(availablePoi)
-				poiFit, newVisitStart, newVisitEnd = doesNewPoiFit(newPoi, day.Visits, visitId, itinerary.DayBeginHour, itinerary.DayEndHour, day.DayName)
-				if poiFit {
+				newPoi, newPoiIndex := drawPoi(availablePoi, random)
+				visits := copyVisits(day.Visits)
+				visits[visitId].Poi = newPoi
+				if tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour) {
 					usedPoiList = append(usedPoiList, newPoi)
 					updatedPoi = newPoi
 					break
@@ -113,29 +153,18 @@ func CrossoverMultipleDays(itinerary1, itinerary2 *Itinerary, allPoiList []*POI)
 			}
 
 			if updatedPoi == nil {
-				currentVisit := &itinerary.Days[dayId].Visits[visitId]
-				if visitId > 0 {
-					prevVisit := &itinerary.Days[dayId].Visits[visitId-1]
-					itinerary.Days[dayId].Visits[visitId-1].EndVisit = minHour(addMinutes(prevVisit.EndVisit,
-						currentVisit.VisitDuration/2), prevVisit.Poi.CloseHour[day.DayName])
-				}
-				if visitId < len(itinerary.Days[dayId].Visits)-1 {
-					nextVisit := &itinerary.Days[dayId].Visits[visitId+1]
-					itinerary.Days[dayId].Visits[visitId+1].StartVisit = maxHour(subtractMinutes(nextVisit.StartVisit,
-						currentVisit.VisitDuration/2), nextVisit.Poi.OpenHour[day.DayName])
-				}
-				itinerary.Days[dayId].Visits = append(itinerary.Days[dayId].Visits[:visitId], itinerary.Days[dayId].Visits[visitId+1:]...)
+				day.Visits = append(day.Visits[:visitId], day.Visits[visitId+1:]...)
 				for ci := changeId + 1; ci < len(poiToChange); ci++ {
 					if poiToChange[ci].DayId == dayId && poiToChange[ci].VisitId > visitId {
 						poiToChange[ci].VisitId -= 1
 					}
 				}
-			} else {
-				itinerary.Days[dayId].Visits[visitId].Poi = updatedPoi
-				itinerary.Days[dayId].Visits[visitId].StartVisit = newVisitStart
-				itinerary.Days[dayId].Visits[visitId].EndVisit = newVisitEnd
 			}
 		}
+		// times of the days with removed duplicates are recomputed for the new order, which must not change locked
+		// days
+		repairSchedule(&itinerary)
+		itinerary.locks.enforce(&itinerary)
 	}
 
 	return child1, child2
@@ -12,6 +12,7 @@ type POI struct {
 	OpenHour     map[string]time.Time `json:"openHour"`
 	CloseHour    map[string]time.Time `json:"closeHour"`
 	Satisfaction float64              `json:"satisfaction"`
+	Categories   []string             `json:"categories"`
 }
 
 type ApiPOI struct {
@@ -21,6 +22,7 @@ type ApiPOI struct {
 	OpenHour     map[string]string `json:"openHour"`
 	CloseHour    map[string]string `json:"closeHour"`
 	Satisfaction float64           `json:"satisfaction"`
+	Categories   []string          `json:"categories,omitempty"`
 }
 
 func (poi *POI) print() string {
@@ -40,30 +42,51 @@ type ApiVisit struct {
 	StartVisit    string
 	EndVisit      string
 	VisitDuration int
+	Locked        bool   `json:"locked,omitempty"` // kept by re-optimization on its day
+	Type          string `json:"type"`             // "visit" of a POI or "break", see SetBreaks
+	Break         string `json:"break,omitempty"`  // name of the break taken during the visit of a POI
 }
 
 type Day struct {
 	Visits    []Visit
 	DayNumber int
 	DayName   string //mon, tue, wed, thu, fri, sat, sun
+	breaks    []Break
+	fixed     []Visit // locked visits which keep their times, see itineraryLocks
+}
+
+type ApiLeg struct {
+	From            string  `json:"from"`
+	To              string  `json:"to"`
+	DistanceMeters  float64 `json:"distanceMeters"`
+	DurationMinutes int     `json:"durationMinutes"`
+	Departure       string  `json:"departure"`
+	Arrival         string  `json:"arrival"`
+	Mode            string  `json:"mode"`
+	Polyline        string  `json:"polyline"`
 }
 
 type ApiDay struct {
 	Visits    []ApiVisit
+	Legs      []ApiLeg `json:"legs"`
 	DayNumber int
 	DayName   string //mon, tue, wed, thu, fri, sat, sun
+	Locked    bool   `json:"locked,omitempty"` // kept unchanged by re-optimization
 }
 
 type Itinerary struct {
-	Days         []Day
-	DayBeginHour time.Time
-	DayEndHour   time.Time
+	Days          []Day
+	DayBeginHour  time.Time
+	DayEndHour    time.Time
+	Accommodation *POI // optional, only used to describe the first and the last leg of each day
+	locks         *itineraryLocks
 }
 
 type ApiItinerary struct {
-	Days         []ApiDay
-	DayBeginHour string
-	DayEndHour   string
+	Days          []ApiDay
+	DayBeginHour  string
+	DayEndHour    string
+	Accommodation *ApiPOI `json:"accommodation,omitempty"`
 }
 
 func (it *Itinerary) ShortPrint() {
@@ -0,0 +1,255 @@
+package genetic_algorithm
+
+import "math/rand"
+
+// Permutation crossovers applied to the order of visits within each day. Parents visit different subsets of POIs,
+// so the classic operators are adapted to sequences of different length and content: a child takes the length
+// of its first parent and may get POIs which only the second parent visits. POIs which are already used on an
+// earlier day of the child are skipped and times are computed by decodeItinerary. Locks of the first parent are
+// applied to the child afterwards.
+
+type dayRecombination func(sequence1, sequence2 []*POI, random *rand.Rand) []*POI
+
+// OrderCrossover (OX) copies a random segment of the first parent and fills the remaining positions with POIs
+// of the second parent in the order they appear after the segment.
+type OrderCrossover struct{}
+
+func (OrderCrossover) name() string {
+	return "ox"
+}
+
+func (OrderCrossover) crossover(parent1, parent2 *Itinerary, allPoiList []*POI, random *rand.Rand) (Itinerary, Itinerary) {
+	return recombineDays(parent1, parent2, orderCrossover, random), recombineDays(parent2, parent1, orderCrossover, random)
+}
+
+// PartiallyMappedCrossover (PMX) copies a random segment of the first parent and takes the other positions from
+// the second parent, resolving conflicts with the mapping defined by the segment.
+type PartiallyMappedCrossover struct{}
+
+func (PartiallyMappedCrossover) name() string {
+	return "pmx"
+}
+
+func (PartiallyMappedCrossover) crossover(parent1, parent2 *Itinerary, allPoiList []*POI,
+	random *rand.Rand) (Itinerary, Itinerary) {
+	return recombineDays(parent1, parent2, partiallyMappedCrossover, random),
+		recombineDays(parent2, parent1, partiallyMappedCrossover, random)
+}
+
+// EdgeRecombinationCrossover (ERX) builds the child from the adjacencies of both parents, preferring POIs which
+// have the fewest remaining neighbours, so that the walking order of both parents is preserved where possible.
+type EdgeRecombinationCrossover struct{}
+
+func (EdgeRecombinationCrossover) name() string {
+	return "erx"
+}
+
+func (EdgeRecombinationCrossover) crossover(parent1, parent2 *Itinerary, allPoiList []*POI,
+	random *rand.Rand) (Itinerary, Itinerary) {
+	return recombineDays(parent1, parent2, edgeRecombination, random),
+		recombineDays(parent2, parent1, edgeRecombination, random)
+}
+
+func daySequence(day Day) []*POI {
+	sequence := make([]*POI, len(day.Visits))
+	for i, visit := range day.Visits {
+		sequence[i] = visit.Poi
+	}
+	return sequence
+}
+
+func recombineDays(parent1, parent2 *Itinerary, recombine dayRecombination, random *rand.Rand) Itinerary {
+	// visit durations are inherited, preferably from the first parent
+	durations := make(map[*POI]int)
+	for _, parent := range []*Itinerary{parent2, parent1} {
+		for _, day := range parent.Days {
+			for _, visit := range day.Visits {
+				durations[visit.Poi] = visit.VisitDuration
+			}
+		}
+	}
+
+	order := make([][]*POI, len(parent1.Days))
+	usedPoi := make(map[*POI]bool)
+	for dayId, day := range parent1.Days {
+		sequence := recombine(daySequence(day), daySequence(parent2.Days[dayId]), random)
+		order[dayId] = make([]*POI, 0, len(sequence))
+		for _, poi := range sequence {
+			if usedPoi[poi] {
+				continue
+			}
+			usedPoi[poi] = true
+			order[dayId] = append(order[dayId], poi)
+		}
+	}
+	// days keep the breaks and the fixed visits of the first parent
+	child := Itinerary{
+		Days:          make([]Day, len(parent1.Days)),
+		DayBeginHour:  parent1.DayBeginHour,
+		DayEndHour:    parent1.DayEndHour,
+		Accommodation: parent1.Accommodation,
+	}
+	for dayId, day := range parent1.Days {
+		child.Days[dayId] = decodeDay(order[dayId], durations, day, parent1.DayBeginHour, parent1.DayEndHour)
+	}
+	parent1.locks.apply(&child)
+	return child
+}
+
+func copySequence(sequence []*POI) []*POI {
+	return append(make([]*POI, 0, len(sequence)), sequence...)
+}
+
+func randomSegment(length int, random *rand.Rand) (int, int) {
+	start := random.Intn(length)
+	end := start + 1 + random.Intn(length-start)
+	return start, end
+}
+
+func orderCrossover(sequence1, sequence2 []*POI, random *rand.Rand) []*POI {
+	if len(sequence1) == 0 || len(sequence2) == 0 {
+		return append(copySequence(sequence1), sequence2...)
+	}
+	start, end := randomSegment(len(sequence1), random)
+	inSegment := make(map[*POI]bool)
+	for _, poi := range sequence1[start:end] {
+		inSegment[poi] = true
+	}
+
+	fill := make([]*POI, 0, len(sequence2))
+	for i := 0; i < len(sequence2); i++ {
+		poi := sequence2[(end+i)%len(sequence2)]
+		if !inSegment[poi] {
+			fill = append(fill, poi)
+		}
+	}
+
+	// positions after the segment are filled first, then the ones before it
+	tailLength := len(sequence1) - end
+	if tailLength > len(fill) {
+		tailLength = len(fill)
+	}
+	headLength := start
+	if headLength > len(fill)-tailLength {
+		headLength = len(fill) - tailLength
+	}
+	child := make([]*POI, 0, len(sequence1))
+	child = append(child, fill[tailLength:tailLength+headLength]...)
+	child = append(child, sequence1[start:end]...)
+	return append(child, fill[:tailLength]...)
+}
+
+func partiallyMappedCrossover(sequence1, sequence2 []*POI, random *rand.Rand) []*POI {
+	if len(sequence1) == 0 || len(sequence2) == 0 {
+		return append(copySequence(sequence1), sequence2...)
+	}
+	start, end := randomSegment(len(sequence1), random)
+	segmentIndex := make(map[*POI]int)
+	for i := start; i < end; i++ {
+		segmentIndex[sequence1[i]] = i
+	}
+
+	child := make([]*POI, 0, len(sequence1))
+	for position := 0; position < len(sequence1); position++ {
+		if position >= start && position < end {
+			child = append(child, sequence1[position])
+			continue
+		}
+		if position >= len(sequence2) {
+			continue
+		}
+		poi := sequence2[position]
+		// follow the mapping sequence1[i] -> sequence2[i] until the POI is not in the segment
+		for steps := 0; poi != nil; steps++ {
+			index, conflict := segmentIndex[poi]
+			if !conflict {
+				break
+			}
+			if index >= len(sequence2) || steps > len(sequence1) {
+				poi = nil
+				break
+			}
+			poi = sequence2[index]
+		}
+		if poi != nil {
+			child = append(child, poi)
+		}
+	}
+	return child
+}
+
+func edgeRecombination(sequence1, sequence2 []*POI, random *rand.Rand) []*POI {
+	if len(sequence1) == 0 || len(sequence2) == 0 {
+		return append(copySequence(sequence1), sequence2...)
+	}
+	neighbours := make(map[*POI][]*POI)
+	candidates := make([]*POI, 0, len(sequence1)+len(sequence2))
+	addNeighbour := func(poi, neighbour *POI) {
+		if !containsPoi(neighbours[poi], neighbour) {
+			neighbours[poi] = append(neighbours[poi], neighbour)
+		}
+	}
+	for _, sequence := range [][]*POI{sequence1, sequence2} {
+		for i, poi := range sequence {
+			if _, known := neighbours[poi]; !known {
+				neighbours[poi] = make([]*POI, 0, 4)
+				candidates = append(candidates, poi)
+			}
+			if i > 0 {
+				addNeighbour(poi, sequence[i-1])
+				addNeighbour(sequence[i-1], poi)
+			}
+		}
+	}
+
+	used := make(map[*POI]bool)
+	remainingNeighbours := func(poi *POI) int {
+		count := 0
+		for _, neighbour := range neighbours[poi] {
+			if !used[neighbour] {
+				count++
+			}
+		}
+		return count
+	}
+
+	current := sequence1[0]
+	if random.Intn(2) == 1 {
+		current = sequence2[0]
+	}
+	child := make([]*POI, 0, len(sequence1))
+	for current != nil && len(child) < len(sequence1) {
+		child = append(child, current)
+		used[current] = true
+
+		var next *POI
+		bestCount := -1
+		ties := 0
+		for _, neighbour := range neighbours[current] {
+			if used[neighbour] {
+				continue
+			}
+			count := remainingNeighbours(neighbour)
+			if bestCount < 0 || count < bestCount {
+				next, bestCount, ties = neighbour, count, 1
+			} else if count == bestCount {
+				// reservoir sampling gives every tied neighbour the same chance
+				ties++
+				if random.Intn(ties) == 0 {
+					next = neighbour
+				}
+			}
+		}
+		if next == nil {
+			unused := make([]*POI, 0)
+			for _, poi := range candidates {
+				if !used[poi] {
+					unused = append(unused, poi)
+				}
+			}
+			next, _ = drawPoi(unused, random)
+		}
+		current = next
+	}
+	return child
+}
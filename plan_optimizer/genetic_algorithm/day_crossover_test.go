@@ -0,0 +1,74 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+var dayRecombinations = map[string]dayRecombination{
+	"order":              orderCrossover,
+	"partially-mapped":   partiallyMappedCrossover,
+	"edge-recombination": edgeRecombination,
+}
+
+var dayCrossovers = []CrossoverOperator{OrderCrossover{}, PartiallyMappedCrossover{}, EdgeRecombinationCrossover{}}
+
+func TestDayRecombinationsKeepPoisUnique(t *testing.T) {
+	random := rand.New(rand.NewSource(17))
+	pois := randomPois(random, 12)
+	for name, recombine := range dayRecombinations {
+		for i := 0; i < 500; i++ {
+			// the parents visit partly different POIs in different orders, one of them maybe none
+			sequence1 := random.Perm(len(pois))[:random.Intn(len(pois)+1)]
+			sequence2 := random.Perm(len(pois))[:random.Intn(len(pois)+1)]
+			parent1, parent2 := make([]*POI, len(sequence1)), make([]*POI, len(sequence2))
+			inParents := make(map[*POI]bool)
+			for j, index := range sequence1 {
+				parent1[j] = pois[index]
+				inParents[pois[index]] = true
+			}
+			for j, index := range sequence2 {
+				parent2[j] = pois[index]
+				inParents[pois[index]] = true
+			}
+
+			seen := make(map[*POI]bool)
+			for _, poi := range recombine(parent1, parent2, random) {
+				if seen[poi] || !inParents[poi] {
+					t.Fatalf("%s: child of %v and %v repeats or invents %s", name, sequence1, sequence2, poi.Name)
+				}
+				seen[poi] = true
+			}
+		}
+	}
+}
+
+func TestDayCrossoversCreateFeasibleChildren(t *testing.T) {
+	random := rand.New(rand.NewSource(18))
+	pois := randomPois(random, 30)
+	for _, days := range [][]string{testDays, testDays[:1]} {
+		geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), days, 0.05, 1000.0, 1.0)
+		for _, poi := range pois {
+			geneticAlgorithm.AddPoi(poi)
+		}
+		empty := geneticAlgorithm.emptyItinerary()
+		for _, operator := range dayCrossovers {
+			for i := 0; i < 200; i++ {
+				parent1 := GenerateRandomItinerary(pois, clock(9*60), clock(19*60), days, random)
+				parent2 := GenerateRandomItinerary(pois, clock(9*60), clock(19*60), days, random)
+				if i%10 == 0 {
+					parent2 = copyItinerary(&empty)
+				}
+				child1, child2 := operator.crossover(&parent1, &parent2, pois, random)
+				for _, child := range []Itinerary{child1, child2} {
+					if _, failed := geneticAlgorithm.evaluateItinerary(&child); failed > 0 {
+						t.Fatalf("%s on %d days: child fails %d constraints", operator.name(), len(days), failed)
+					}
+					if len(child.Days) != len(days) {
+						t.Fatalf("%s on %d days: child has %d days", operator.name(), len(days), len(child.Days))
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package genetic_algorithm
+
+import (
+	"math/bits"
+	"time"
+)
+
+// populationDiversity is the mean Hamming distance between the sets of POIs visited by every pair of solutions,
+// divided by twice the mean number of visited POIs. It is 0 when all solutions visit the same POIs and 1 when no
+// two of them share a POI. The mean distance is computed from the share of solutions visiting every POI, so the
+// population is not compared pair by pair.
+func populationDiversity(population []solution) float64 {
+	if len(population) < 2 {
+		return 0.0
+	}
+	visits := make(map[*POI]int)
+	totalPois := 0
+	for _, sol := range population {
+		for _, day := range sol.itinerary.Days {
+			for _, visit := range day.Visits {
+				visits[visit.Poi]++
+				totalPois++
+			}
+		}
+	}
+	if totalPois == 0 {
+		return 0.0
+	}
+	n := float64(len(population))
+	// a pair differs in a POI when exactly one of the solutions visits it
+	distance := 0.0
+	for _, count := range visits {
+		share := float64(count) / n
+		distance += 2.0 * share * (1.0 - share)
+	}
+	distance *= n / (n - 1.0)
+	return distance / (2.0 * float64(totalPois) / n)
+}
+
+// keySet is a set of keys of a solution, like the POIs it visits, stored as a bitset over indices of the keys.
+type keySet []uint64
+
+// poiSets returns the sets of POIs of all solutions, indexed consistently so that they can be compared.
+func poiSets(solutions []solution) []keySet {
+	return keySets(solutions, visitedPois)
+}
+
+func visitedPois(itinerary *Itinerary) []interface{} {
+	pois := make([]interface{}, 0)
+	for _, day := range itinerary.Days {
+		for _, visit := range day.Visits {
+			pois = append(pois, visit.Poi)
+		}
+	}
+	return pois
+}
+
+// keySets returns the sets of keys of the itineraries of all solutions, indexed consistently so that they can be
+// compared.
+func keySets(solutions []solution, keys func(itinerary *Itinerary) []interface{}) []keySet {
+	index := make(map[interface{}]int)
+	solutionKeys := make([][]interface{}, len(solutions))
+	for i := range solutions {
+		solutionKeys[i] = keys(&solutions[i].itinerary)
+		for _, key := range solutionKeys[i] {
+			if _, ok := index[key]; !ok {
+				index[key] = len(index)
+			}
+		}
+	}
+	words := (len(index) + 63) / 64
+	sets := make([]keySet, len(solutions))
+	for i := range solutions {
+		sets[i] = make(keySet, words)
+		for _, key := range solutionKeys[i] {
+			bit := index[key]
+			sets[i][bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return sets
+}
+
+// distance is the Jaccard distance between two sets: one minus the size of their intersection divided by the size
+// of their union.
+func (a keySet) distance(b keySet) float64 {
+	common, union := 0, 0
+	for i := range a {
+		common += bits.OnesCount64(a[i] & b[i])
+		union += bits.OnesCount64(a[i] | b[i])
+	}
+	if union == 0 {
+		return 0.0
+	}
+	return 1.0 - float64(common)/float64(union)
+}
+
+// Restart configures a partial restart of the population: when its diversity drops below Threshold, all solutions
+// except the best Elite ones are replaced by random itineraries. Elite is 10% of the population by default. With
+// a diversity termination criterion the threshold should be higher than its minimum diversity.
+type Restart struct {
+	Threshold float64
+	Elite     int
+}
+
+// SetRestart enables restarts of a population which lost its diversity, nil disables them.
+func (ga *GeneticAlgorithm) SetRestart(restart *Restart) {
+	ga.restart = restart
+}
+
+// restartIfConverged replaces the population apart from its elite with random solutions when its diversity is
+// below the threshold.
+func (ga *GeneticAlgorithm) restartIfConverged() {
+	if ga.restart == nil || ga.restart.Threshold <= 0 ||
+		populationDiversity(ga.population) >= ga.restart.Threshold {
+		return
+	}
+	start := time.Now()
+	elite := ga.restart.Elite
+	if elite <= 0 {
+		elite = ga.populationSize / 10
+	}
+	if elite < 1 {
+		elite = 1
+	}
+	if elite > len(ga.population) {
+		elite = len(ga.population)
+	}
+	kept := ga.population[:elite]
+	ga.createInitialPopulation(ga.populationSize-elite, 0)
+	ga.population = mergeSolutions(kept, ga.population)
+	ga.sortPopulation()
+	ga.restarts++
+	ga.timePhase("restart", start)
+}
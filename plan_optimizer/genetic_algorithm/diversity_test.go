@@ -0,0 +1,113 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// solutionVisiting creates a solution with one day visiting the POIs in the given order.
+func solutionVisiting(objective float64, pois ...*POI) solution {
+	visits := make([]Visit, len(pois))
+	for i, poi := range pois {
+		visits[i] = Visit{Poi: poi}
+	}
+	return solution{itinerary: Itinerary{Days: []Day{{Visits: visits}}}, objectiveValue: objective}
+}
+
+func TestPopulationDiversity(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(10)), 12)
+	identical := []solution{
+		solutionVisiting(1, pois[0], pois[1], pois[2]),
+		solutionVisiting(2, pois[2], pois[0], pois[1]),
+		solutionVisiting(3, pois[1], pois[2], pois[0]),
+	}
+	if diversity := populationDiversity(identical); diversity != 0 {
+		t.Errorf("solutions visiting the same POIs have diversity %f", diversity)
+	}
+	disjoint := []solution{
+		solutionVisiting(1, pois[0], pois[1], pois[2]),
+		solutionVisiting(2, pois[3], pois[4], pois[5]),
+		solutionVisiting(3, pois[6], pois[7], pois[8]),
+		solutionVisiting(4, pois[9], pois[10], pois[11]),
+	}
+	if diversity := populationDiversity(disjoint); math.Abs(diversity-1) > 1e-9 {
+		t.Errorf("solutions without common POIs have diversity %f", diversity)
+	}
+	// a pair sharing one of three POIs has a Hamming distance of 4 out of 6
+	pair := []solution{solutionVisiting(1, pois[0], pois[1], pois[2]), solutionVisiting(2, pois[2], pois[3], pois[4])}
+	if diversity := populationDiversity(pair); math.Abs(diversity-2.0/3.0) > 1e-9 {
+		t.Errorf("solutions sharing a POI have diversity %f, expected %f", diversity, 2.0/3.0)
+	}
+}
+
+func TestJaccardDistanceOfKeySets(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(10)), 4)
+	solutions := []solution{
+		solutionVisiting(1, pois[0], pois[1], pois[2]),
+		solutionVisiting(2, pois[2], pois[1], pois[0]),
+		solutionVisiting(3, pois[1], pois[2], pois[3]),
+		solutionVisiting(4),
+	}
+	sets := poiSets(solutions)
+	for _, test := range []struct {
+		a, b     int
+		distance float64
+	}{{0, 1, 0}, {0, 2, 0.5}, {2, 0, 0.5}, {0, 3, 1}, {3, 3, 0}} {
+		if distance := sets[test.a].distance(sets[test.b]); math.Abs(distance-test.distance) > 1e-9 {
+			t.Errorf("distance between solutions %d and %d is %f, expected %f", test.a, test.b, distance,
+				test.distance)
+		}
+	}
+
+	// the reversed order only counts as another itinerary when the order is compared
+	for _, ordering := range []bool{false, true} {
+		alternatives := &Alternatives{MinimumDistance: 0.4, Ordering: ordering}
+		selected := alternatives.selectDistinct(solutions[:3], 3)
+		expected := 2
+		if ordering {
+			expected = 3
+		}
+		if len(selected) != expected {
+			t.Errorf("ordering %t: %d distinct solutions selected, expected %d", ordering, len(selected), expected)
+		}
+	}
+}
+
+func TestRestartKeepsElite(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(11)), 20)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	geneticAlgorithm.SetRunParameters(10, 1, 8)
+	geneticAlgorithm.SetRestart(&Restart{Threshold: 0.2, Elite: 2})
+	geneticAlgorithm.startRun()
+
+	// the converged population visits the same POIs, the elite has objectives no random itinerary can reach
+	geneticAlgorithm.population = make([]solution, 10)
+	for i := range geneticAlgorithm.population {
+		geneticAlgorithm.population[i] = solutionVisiting(float64(1000-i), pois[0], pois[1])
+	}
+	geneticAlgorithm.restartIfConverged()
+
+	population := geneticAlgorithm.population
+	if geneticAlgorithm.restarts != 1 || len(population) != 10 {
+		t.Fatalf("%d restarts, population of %d", geneticAlgorithm.restarts, len(population))
+	}
+	if population[0].objectiveValue != 1000 || population[1].objectiveValue != 999 {
+		t.Errorf("elite was not kept, best objectives %f and %f", population[0].objectiveValue,
+			population[1].objectiveValue)
+	}
+	for _, sol := range population[2:] {
+		if sol.objectiveValue > 900 {
+			t.Errorf("solution %f of the converged population survived the restart", sol.objectiveValue)
+		}
+	}
+
+	// a diverse population is not restarted
+	geneticAlgorithm.restartIfConverged()
+	if geneticAlgorithm.restarts != 1 {
+		t.Errorf("diverse population with diversity %f was restarted", populationDiversity(population))
+	}
+}
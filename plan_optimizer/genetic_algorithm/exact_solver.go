@@ -0,0 +1,369 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"time"
+)
+
+// ExactSolverMaxPois is the largest number of POIs accepted by ExactSolver, its memory grows with 2^n.
+const ExactSolverMaxPois = 15
+
+// ExactSolver finds the optimal itinerary of small instances with dynamic programming. It uses the same time
+// windows, travel times, minimum visit duration and objective as the other solvers, with times in whole minutes.
+//
+// For every day, set of POIs and last POI it keeps the schedules which are not dominated. In a schedule every
+// visit lasts minimumVisitDuration minutes plus the time which would otherwise be spent waiting for the next POI
+// to open. The objective grows linearly with visit durations, so the time left until the last POI closes is worth
+// the most when it is given to the visits with the highest satisfaction which can still be extended; each schedule
+// keeps these visits and by how much they can be extended. Days are then combined over disjoint sets of POIs.
+type ExactSolver struct {
+	problem
+}
+
+func CreateExactSolver(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) *ExactSolver {
+	return &ExactSolver{
+		problem: createProblem(dayBeginHour, dayEndHour, daysList, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier),
+	}
+}
+
+// exactExtension says that the visit at the given position of the day can last capacity minutes longer, which adds
+// weight to the objective value per minute. Extending an earlier visit moves all following visits.
+type exactExtension struct {
+	weight   float64
+	capacity int
+	position int
+}
+
+// exactLabel is a schedule of one day ending with the visit of poi. extensions are sorted by weight from the
+// highest and their total capacity never exceeds the time left until the last POI closes.
+type exactLabel struct {
+	poi        int
+	position   int
+	start      int
+	value      float64
+	extensions []exactExtension
+	prev       *exactLabel
+}
+
+// exactDay holds the time windows of POIs on one day in minutes from the beginning of the day.
+type exactDay struct {
+	open          []int
+	closing       []int
+	weight        []float64
+	travel        [][]int
+	poiMultiplier float64
+}
+
+// Solve computes the optimal itinerary. The context is checked between days. Instances with more than
+// ExactSolverMaxPois POIs are not solved, the itinerary is empty and the report gives StopTooManyPois as the reason.
+func (e *ExactSolver) Solve(ctx context.Context) (ApiItinerary, RunReport) {
+	e.startRun()
+	pois := e.poiList
+	if len(pois) > ExactSolverMaxPois {
+		e.stopReason = StopTooManyPois
+		return e.result(decodeItinerary(nil, nil, e.dayBeginHour, e.dayEndHour, e.daysList)), e.Report()
+	}
+	masks := 1 << len(pois)
+
+	// combined[mask] is the best value of the days solved so far visiting exactly the POIs of mask
+	combined := make([]float64, masks)
+	for mask := 1; mask < masks; mask++ {
+		combined[mask] = -1
+	}
+	days := make([]exactDay, len(e.daysList))
+	dayBest := make([][]*exactLabel, len(e.daysList))
+	daySet := make([][]int, len(e.daysList))
+	for dayNumber, dayName := range e.daysList {
+		if ctx.Err() != nil {
+			break
+		}
+		days[dayNumber] = e.createExactDay(pois, dayName)
+		values, labels := days[dayNumber].solve()
+		dayBest[dayNumber] = labels
+		next := make([]float64, masks)
+		choice := make([]int, masks)
+		for mask := 0; mask < masks; mask++ {
+			next[mask] = -1
+			// iterate over all subsets of mask visited on this day
+			for sub := mask; ; sub = (sub - 1) & mask {
+				if values[sub] >= 0 && combined[mask^sub] >= 0 && values[sub]+combined[mask^sub] > next[mask] {
+					next[mask] = values[sub] + combined[mask^sub]
+					choice[mask] = sub
+				}
+				if sub == 0 {
+					break
+				}
+			}
+		}
+		combined = next
+		daySet[dayNumber] = choice
+	}
+
+	bestMask := 0
+	for mask := 0; mask < masks; mask++ {
+		if combined[mask] > combined[bestMask] {
+			bestMask = mask
+		}
+	}
+	e.recordOperator("construction", "dynamic programming", bestMask != 0)
+
+	itinerary := decodeItinerary(nil, nil, e.dayBeginHour, e.dayEndHour, e.daysList)
+	mask := bestMask
+	for dayNumber := len(e.daysList) - 1; dayNumber >= 0; dayNumber-- {
+		if daySet[dayNumber] == nil {
+			continue
+		}
+		sub := daySet[dayNumber][mask]
+		mask ^= sub
+		if label := dayBest[dayNumber][sub]; label != nil {
+			itinerary.Days[dayNumber].Visits = days[dayNumber].visits(label, pois, e.dayBeginHour)
+		}
+	}
+	return e.result(itinerary), e.Report()
+}
+
+func (e *ExactSolver) createExactDay(pois []*POI, dayName string) exactDay {
+	n := len(pois)
+	day := exactDay{
+		open:          make([]int, n),
+		closing:       make([]int, n),
+		weight:        make([]float64, n),
+		travel:        make([][]int, n),
+		poiMultiplier: e.poiMultiplier,
+	}
+	for i, poi := range pois {
+		day.open[i] = calculateDuration(e.dayBeginHour, maxHour(e.dayBeginHour, poi.OpenHour[dayName]))
+		day.closing[i] = calculateDuration(e.dayBeginHour, minHour(e.dayEndHour, poi.CloseHour[dayName]))
+		day.weight[i] = e.satisfactionMultiplier * poi.Satisfaction / (24.0 * 60.0)
+		day.travel[i] = make([]int, n)
+		for j := range pois {
+			if i != j {
+				day.travel[i][j] = transport(pois[i], pois[j])
+			}
+		}
+	}
+	return day
+}
+
+// solve returns for every set of POIs the best value of a day visiting exactly these POIs, or -1 if they cannot
+// be visited in one day, together with the last label of the best schedule.
+func (day *exactDay) solve() ([]float64, []*exactLabel) {
+	n := len(day.open)
+	masks := 1 << n
+	fronts := make([][]*exactLabel, masks*n)
+	for poi := 0; poi < n; poi++ {
+		if label, ok := day.extend(nil, poi); ok {
+			day.insert(fronts, (1<<poi)*n+poi, label)
+		}
+	}
+
+	values := make([]float64, masks)
+	best := make([]*exactLabel, masks)
+	for mask := 1; mask < masks; mask++ {
+		values[mask] = -1
+		for last := 0; last < n; last++ {
+			for _, label := range fronts[mask*n+last] {
+				// the time left until the last POI closes is given to the visits
+				value := label.value + extensionValue(label.extensions, day.slack(label))
+				if value > values[mask] {
+					values[mask] = value
+					best[mask] = label
+				}
+				for next := 0; next < n; next++ {
+					if mask&(1<<next) != 0 {
+						continue
+					}
+					if nextLabel, ok := day.extend(label, next); ok {
+						day.insert(fronts, (mask|1<<next)*n+next, nextLabel)
+					}
+				}
+			}
+		}
+	}
+	return values, best
+}
+
+// slack is the number of minutes the visits of the schedule can be extended by in total.
+func (day *exactDay) slack(label *exactLabel) int {
+	return day.closing[label.poi] - label.start - minimumVisitDuration
+}
+
+// extend adds the visit of poi after the last visit of the label. Waiting for the POI to open is spent on the
+// previous visits, so the only reason to wait is that none of them can be extended any more. ok is false if the
+// POI cannot be visited for minimumVisitDuration minutes.
+func (day *exactDay) extend(label *exactLabel, poi int) (exactLabel, bool) {
+	next := exactLabel{poi: poi, start: day.open[poi], prev: label}
+	var inherited []exactExtension
+	if label != nil {
+		next.position = label.position + 1
+		next.value = label.value
+		arrival := label.start + minimumVisitDuration + day.travel[label.poi][poi]
+		if arrival > next.start {
+			next.start = arrival
+		}
+		slack := day.slack(label)
+		wait := day.open[poi] - arrival
+		if wait > slack {
+			wait = slack
+		}
+		if wait > 0 {
+			next.value += extensionValue(label.extensions, wait)
+		} else {
+			wait = 0
+		}
+		// extending earlier visits later cannot move the previous one past its closing hour
+		inherited = limitExtensions(allocateExtensions(label.extensions, wait, nil), slack-wait)
+	}
+	nextSlack := day.slack(&next)
+	if nextSlack < 0 {
+		return next, false
+	}
+	next.value += day.poiMultiplier + day.weight[poi]*float64(minimumVisitDuration)
+
+	// time given to earlier visits with a lower weight is better spent on the new visit
+	next.extensions = make([]exactExtension, 0, len(inherited)+1)
+	for _, extension := range inherited {
+		if extension.weight > day.weight[poi] {
+			next.extensions = append(next.extensions, extension)
+		}
+	}
+	next.extensions = limitExtensions(append(next.extensions, exactExtension{
+		weight:   day.weight[poi],
+		capacity: nextSlack,
+		position: next.position,
+	}), nextSlack)
+	return next, true
+}
+
+// insert adds the label to the Pareto front of its set and last POI, unless another label starts the last visit
+// earlier and is at least as good for every extension, and removes labels it dominates.
+func (day *exactDay) insert(fronts [][]*exactLabel, key int, label exactLabel) {
+	front := fronts[key]
+	for _, other := range front {
+		if day.dominates(other, &label) {
+			return
+		}
+	}
+	kept := front[:0]
+	for _, other := range front {
+		if !day.dominates(&label, other) {
+			kept = append(kept, other)
+		}
+	}
+	fronts[key] = append(kept, &label)
+}
+
+func (day *exactDay) dominates(a, b *exactLabel) bool {
+	if a.start > b.start || a.value < b.value {
+		return false
+	}
+	// both values are piecewise linear, so it is enough to compare them where any of them changes its slope
+	slack := day.slack(b)
+	for _, extensions := range [][]exactExtension{a.extensions, b.extensions} {
+		minutes := 0
+		for _, extension := range extensions {
+			minutes += extension.capacity
+			if minutes > slack {
+				break
+			}
+			if a.value+extensionValue(a.extensions, minutes) < b.value+extensionValue(b.extensions, minutes) {
+				return false
+			}
+		}
+	}
+	return a.value+extensionValue(a.extensions, slack) >= b.value+extensionValue(b.extensions, slack)
+}
+
+// visits computes the times of the schedule ending with the label.
+func (day *exactDay) visits(label *exactLabel, pois []*POI, dayBeginHour time.Time) []Visit {
+	labels := make([]*exactLabel, label.position+1)
+	for ; label != nil; label = label.prev {
+		labels[label.position] = label
+	}
+	extra := make([]int, len(labels))
+	for i := 0; i+1 < len(labels); i++ {
+		arrival := labels[i].start + minimumVisitDuration + day.travel[labels[i].poi][labels[i+1].poi]
+		wait := day.open[labels[i+1].poi] - arrival
+		if slack := day.slack(labels[i]); wait > slack {
+			wait = slack
+		}
+		if wait > 0 {
+			allocateExtensions(labels[i].extensions, wait, extra)
+		}
+	}
+	last := labels[len(labels)-1]
+	allocateExtensions(last.extensions, day.slack(last), extra)
+
+	visits := make([]Visit, len(labels))
+	end := 0
+	for i, label := range labels {
+		start := day.open[label.poi]
+		if i > 0 && end+day.travel[labels[i-1].poi][label.poi] > start {
+			start = end + day.travel[labels[i-1].poi][label.poi]
+		}
+		end = start + minimumVisitDuration + extra[i]
+		visits[i] = Visit{
+			Poi:           pois[label.poi],
+			StartVisit:    addMinutes(dayBeginHour, start),
+			EndVisit:      addMinutes(dayBeginHour, end),
+			VisitDuration: end - start,
+		}
+	}
+	return visits
+}
+
+// extensionValue is the value of extending the visits by the given number of minutes in total.
+func extensionValue(extensions []exactExtension, minutes int) float64 {
+	value := 0.0
+	for _, extension := range extensions {
+		if minutes <= 0 {
+			break
+		}
+		used := extension.capacity
+		if used > minutes {
+			used = minutes
+		}
+		value += extension.weight * float64(used)
+		minutes -= used
+	}
+	return value
+}
+
+// allocateExtensions gives the minutes to the visits with the highest weight first. It returns the extensions
+// which are left and adds the minutes given to every visit to extra, if it is not nil.
+func allocateExtensions(extensions []exactExtension, minutes int, extra []int) []exactExtension {
+	left := make([]exactExtension, 0, len(extensions))
+	for _, extension := range extensions {
+		used := extension.capacity
+		if used > minutes {
+			used = minutes
+		}
+		minutes -= used
+		if extra != nil {
+			extra[extension.position] += used
+		}
+		if extension.capacity > used {
+			extension.capacity -= used
+			left = append(left, extension)
+		}
+	}
+	return left
+}
+
+// limitExtensions cuts the extensions with the lowest weight, so that the total capacity is at most limit.
+func limitExtensions(extensions []exactExtension, limit int) []exactExtension {
+	limited := extensions[:0]
+	for _, extension := range extensions {
+		if limit <= 0 {
+			break
+		}
+		if extension.capacity > limit {
+			extension.capacity = limit
+		}
+		limit -= extension.capacity
+		limited = append(limited, extension)
+	}
+	return limited
+}
@@ -0,0 +1,220 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var testDays = []string{"mon", "tue"}
+
+func clock(minutes int) time.Time {
+	return addMinutes(time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), minutes)
+}
+
+// randomPois places POIs within a few kilometers of each other with random satisfaction and opening hours.
+func randomPois(random *rand.Rand, n int) []*POI {
+	pois := make([]*POI, n)
+	for i := range pois {
+		pois[i] = &POI{
+			Name:         fmt.Sprintf("poi %d", i),
+			Lat:          50.04 + 0.03*random.Float64(),
+			Lon:          19.92 + 0.04*random.Float64(),
+			Satisfaction: 1 + 9*random.Float64(),
+			OpenHour:     make(map[string]time.Time),
+			CloseHour:    make(map[string]time.Time),
+		}
+		for _, day := range testDays {
+			open := 8*60 + 30*random.Intn(13)
+			pois[i].OpenHour[day] = clock(open)
+			pois[i].CloseHour[day] = clock(open + 90 + 30*random.Intn(14))
+		}
+	}
+	return pois
+}
+
+func createTestExactSolver(pois []*POI, days []string) *ExactSolver {
+	solver := CreateExactSolver(clock(9*60), clock(19*60), days, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		solver.AddPoi(poi)
+	}
+	return solver
+}
+
+// evaluateResult returns the objective value and the number of failed constraints of an itinerary returned by a
+// solver.
+func evaluateResult(t *testing.T, p *problem, result ApiItinerary, pois []*POI) (float64, int) {
+	itinerary, err := convertFromApiItinerary(&result, pois)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.evaluateItinerary(&itinerary)
+}
+
+// bruteForceSequence returns the best value of visiting the POIs in the given order on one day, or -Inf if they
+// cannot be visited, by trying every end of every visit in whole minutes.
+func bruteForceSequence(p *problem, sequence []*POI, dayName string) float64 {
+	length := calculateDuration(p.dayBeginHour, p.dayEndHour)
+	// ends[e] is the best value of the visits so far when the last one ends at minute e
+	var ends []float64
+	for i, poi := range sequence {
+		open := calculateDuration(p.dayBeginHour, maxHour(p.dayBeginHour, poi.OpenHour[dayName]))
+		closing := calculateDuration(p.dayBeginHour, minHour(p.dayEndHour, poi.CloseHour[dayName]))
+		weight := p.satisfactionMultiplier * poi.Satisfaction / (24.0 * 60.0)
+		// reachable[s] is the best value of the previous visits when this one can start at minute s
+		reachable := make([]float64, length+1)
+		for s := range reachable {
+			reachable[s] = math.Inf(-1)
+			if i == 0 {
+				reachable[s] = 0
+			} else if e := s - transport(sequence[i-1], poi); e >= 0 {
+				reachable[s] = ends[e]
+				if s > 0 && reachable[s-1] > reachable[s] {
+					reachable[s] = reachable[s-1]
+				}
+			} else if s > 0 {
+				reachable[s] = reachable[s-1]
+			}
+		}
+		next := make([]float64, length+1)
+		best := math.Inf(-1)
+		for e := range next {
+			next[e] = math.Inf(-1)
+			if s := e - minimumVisitDuration; s >= open && s <= length {
+				if candidate := reachable[s] - weight*float64(s); candidate > best {
+					best = candidate
+				}
+			}
+			if e <= closing && !math.IsInf(best, -1) {
+				next[e] = best + weight*float64(e) + p.poiMultiplier
+			}
+		}
+		// ends is the best value of visits ending at e or earlier
+		for e := 1; e <= length; e++ {
+			if next[e-1] > next[e] {
+				next[e] = next[e-1]
+			}
+		}
+		ends = next
+	}
+	if len(sequence) == 0 {
+		return 0
+	}
+	return ends[length]
+}
+
+// bruteForceBest tries all assignments of POIs to days and all orders of visits within each day.
+func bruteForceBest(p *problem, pois []*POI) float64 {
+	n := len(pois)
+	// dayBest[day][mask] is the best value of visiting exactly the POIs of mask on the day
+	dayBest := make([][]float64, len(p.daysList))
+	for day, dayName := range p.daysList {
+		dayBest[day] = make([]float64, 1<<n)
+		for mask := range dayBest[day] {
+			dayBest[day][mask] = math.Inf(-1)
+		}
+		var permute func(sequence []*POI, mask int)
+		permute = func(sequence []*POI, mask int) {
+			value := bruteForceSequence(p, sequence, dayName)
+			if math.IsInf(value, -1) {
+				// no longer sequence starting like this one is feasible
+				return
+			}
+			if value > dayBest[day][mask] {
+				dayBest[day][mask] = value
+			}
+			for i, poi := range pois {
+				if mask&(1<<i) == 0 {
+					permute(append(sequence[:len(sequence):len(sequence)], poi), mask|1<<i)
+				}
+			}
+		}
+		permute(nil, 0)
+	}
+	best := math.Inf(-1)
+	var assign func(day int, used int, value float64)
+	assign = func(day int, used int, value float64) {
+		if day == len(p.daysList) {
+			if value > best {
+				best = value
+			}
+			return
+		}
+		free := (1<<n - 1) &^ used
+		for mask := free; ; mask = (mask - 1) & free {
+			if !math.IsInf(dayBest[day][mask], -1) {
+				assign(day+1, used|mask, value+dayBest[day][mask])
+			}
+			if mask == 0 {
+				break
+			}
+		}
+	}
+	assign(0, 0, 0)
+	return best
+}
+
+func TestExactSolverMatchesBruteForce(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for instance := 0; instance < 6; instance++ {
+		n := 5 + instance%2
+		days := testDays[:1+instance%2]
+		pois := randomPois(random, n)
+		solver := createTestExactSolver(pois, days)
+		result, _ := solver.Solve(context.Background())
+		value, failed := evaluateResult(t, &solver.problem, result, pois)
+		if failed != 0 {
+			t.Errorf("instance %d: exact solution fails %d constraints", instance, failed)
+		}
+		if best := bruteForceBest(&solver.problem, pois); math.Abs(value-best) > 1e-9 {
+			t.Errorf("instance %d with %d POIs and %d days: exact solver found %f, brute force %f", instance, n,
+				len(days), value, best)
+		}
+	}
+}
+
+func TestExactSolverIsNotBeatenByGeneticAlgorithm(t *testing.T) {
+	if testing.Short() {
+		t.Skip("long genetic algorithm runs")
+	}
+	random := rand.New(rand.NewSource(2))
+	for instance := 0; instance < 4; instance++ {
+		pois := randomPois(random, 8)
+		solver := createTestExactSolver(pois, testDays)
+		result, _ := solver.Solve(context.Background())
+		exact, failed := evaluateResult(t, &solver.problem, result, pois)
+		if failed != 0 {
+			t.Errorf("instance %d: exact solution fails %d constraints", instance, failed)
+		}
+		for seed := int64(1); seed <= 2; seed++ {
+			geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+			for _, poi := range pois {
+				geneticAlgorithm.AddPoi(poi)
+			}
+			geneticAlgorithm.SetRunParameters(200, 1000, 8)
+			geneticAlgorithm.SetSeed(seed)
+			result, _ := geneticAlgorithm.Solve(context.Background())
+			value, failed := evaluateResult(t, &solver.problem, result, pois)
+			if failed == 0 && value > exact+1e-9 {
+				t.Errorf("instance %d: genetic algorithm with seed %d found %f, better than exact %f", instance, seed,
+					value, exact)
+			}
+		}
+	}
+}
+
+func TestExactSolverRejectsTooManyPois(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(3)), ExactSolverMaxPois+1)
+	result, report := createTestExactSolver(pois, testDays).Solve(context.Background())
+	if report.StopReason != StopTooManyPois {
+		t.Errorf("stop reason %q, want %q", report.StopReason, StopTooManyPois)
+	}
+	for _, day := range result.Days {
+		if len(day.Visits) != 0 {
+			t.Errorf("day %d of too large instance has %d visits", day.DayNumber, len(day.Visits))
+		}
+	}
+}
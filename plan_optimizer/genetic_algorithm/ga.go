@@ -1,258 +1,290 @@
 package genetic_algorithm
 
 import (
-	"encoding/csv"
-	"math/rand"
-	"os"
+	"context"
+	"runtime"
 	"sort"
-	"strconv"
-	"sync"
 	"time"
 )
 
 type solution struct {
-	itinerary      Itinerary
-	age            int
-	objectiveValue float64
+	itinerary         Itinerary
+	age               int
+	objectiveValue    float64
+	origin            offspringOrigin
+	objectives        ObjectiveVector
+	failedConstraints int
+}
+
+// offspringOrigin records how an offspring was created, so that operators can be credited for improvements.
+type offspringOrigin struct {
+	crossover       string
+	mutation        string
+	parentObjective float64
 }
 
 type GeneticAlgorithm struct {
-	population             []solution
-	poiList                []*POI
-	constraints            Constraint
-	dayBeginHour           time.Time
-	dayEndHour             time.Time
-	daysList               []string
-	poiMultiplier          float64
-	penaltyMultiplier      float64
-	satisfactionMultiplier float64
+	problem
+	population         []solution
+	selection          SelectionStrategy
+	replacement        ReplacementStrategy
+	crossoverOperators []CrossoverOperator
+	mutation           MutationSelection
+	populationSize     int
+	iterations         int
+	solutionTTL        int
+	localSearch        *LocalSearch
+	alternatives       *Alternatives
+	archive            []solution
+	islandModel        *IslandModel
+	termination        *Termination
+	restart            *Restart
+	seeding            *Seeding
+	warmStart          *warmStart
+	locks              *itineraryLocks
+	workers            int
 }
 
 const MUTATION_PROBABILITY = 0.2
 const DAY_MUTATION_PROBABILITY = 0.6
 
-func saveValuesToFile(bestValues []float64) {
-	fileName := "results.csv"
-
-	// Create the CSV file or open it in append mode
-	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		panic(err)
+func CreateGeneticAlgorithm(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) (ga *GeneticAlgorithm) {
+	ga = &GeneticAlgorithm{
+		problem: createProblem(dayBeginHour, dayEndHour, daysList, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier),
+		selection:      RouletteWheelSelection{},
+		populationSize: 300,
+		iterations:     100,
+		solutionTTL:    8,
 	}
-	defer file.Close()
+	return ga
+}
 
-	// Create a CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+// SetRunParameters sets the parameters used by Solve: the size of the population, the number of iterations and
+// the number of generations a solution survives with the default replacement strategy.
+func (ga *GeneticAlgorithm) SetRunParameters(populationSize int, iterations int, solutionTTL int) {
+	ga.populationSize = populationSize
+	ga.iterations = iterations
+	ga.solutionTTL = solutionTTL
+}
 
-	// Check if the file is new and write the header if necessary
-	fileInfo, err := file.Stat()
-	if err != nil {
-		panic(err)
-	}
+// SetSelectionStrategy changes how parents are selected for crossover. Roulette wheel selection is used by default.
+func (ga *GeneticAlgorithm) SetSelectionStrategy(strategy SelectionStrategy) {
+	ga.selection = strategy
+}
 
-	if fileInfo.Size() == 0 {
-		// Write the header if the file is empty
-		header := []string{"iteration", "best_value"}
-		if err := writer.Write(header); err != nil {
-			panic(err)
-		}
+// workerCount is the number of goroutines creating solutions, GOMAXPROCS unless set by the island model.
+func (ga *GeneticAlgorithm) workerCount() int {
+	if ga.workers > 0 {
+		return ga.workers
 	}
+	return runtime.GOMAXPROCS(0)
+}
 
-	// Append records for each iteration
-	for iteration, bestValue := range bestValues {
-		record := []string{
-			strconv.Itoa(iteration + 1), // Iteration numbers typically start from 1
-			strconv.FormatFloat(bestValue, 'f', 10, 64),
-		}
-		if err := writer.Write(record); err != nil {
-			panic(err)
-		}
-	}
+func sortSolutions(solutions []solution) {
+	sort.SliceStable(solutions, func(i, j int) bool {
+		return solutions[i].objectiveValue > solutions[j].objectiveValue
+	})
 }
 
-func CreateGeneticAlgorithm(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
-	penaltyMultiplier float64, satisfactionMultiplier float64) (ga *GeneticAlgorithm) {
-	visitsWithinDayLimits := &VisitsWithinDayLimits{}
+func (ga *GeneticAlgorithm) sortPopulation() {
+	sortSolutions(ga.population)
+}
 
-	timeDifferenceBetweenPoints := &TimeDifferenceBetweenPoints{}
-	timeDifferenceBetweenPoints.setNext(visitsWithinDayLimits)
+// SetReplacementStrategy changes how the next population is formed from parents and offspring. By default
+// solutions older than the TTL set by SetRunParameters are removed, except the best one.
+func (ga *GeneticAlgorithm) SetReplacementStrategy(strategy ReplacementStrategy) {
+	ga.replacement = strategy
+}
 
-	poiOpenedDuringVisit := &PoiOpenedDuringVisit{}
-	poiOpenedDuringVisit.setNext(timeDifferenceBetweenPoints)
+func (ga *GeneticAlgorithm) Run(initialPopulationSize int, iterations int, solutionTTL int) ApiItinerary {
+	ga.SetRunParameters(initialPopulationSize, iterations, solutionTTL)
+	itinerary, _ := ga.Solve(context.Background())
+	return itinerary
+}
 
-	minimumTimeInPoi := &MinimumTimeInPoi{}
-	minimumTimeInPoi.setNext(poiOpenedDuringVisit)
+// Solve runs the algorithm with the parameters set by SetRunParameters.
+func (ga *GeneticAlgorithm) Solve(ctx context.Context) (ApiItinerary, RunReport) {
+	ga.startRun()
+	if ga.mutation == nil {
+		ga.mutation = CreateAdaptivePursuit(AllMutationOperators(), MUTATION_PROBABILITY)
+	}
+	ga.archive = nil
+	check, ctx, cancel := ga.startTermination(ctx)
+	defer cancel()
+	if ga.islandModel != nil && ga.islandModel.Islands > 1 {
+		return ga.solveIslands(ctx, check)
+	}
+	replacement := ga.replacementStrategy()
+	start := time.Now()
+	ga.createInitialPopulation(ga.populationSize, ga.seededCount(ga.populationSize))
+	ga.sortPopulation()
+	ga.timePhase("initialization", start)
+
+	best := solution{objectiveValue: -1000000.0}
+	if len(ga.population) > 0 {
+		best = ga.population[0]
+	}
+	ga.recordGeneration(0, ga.population)
 
-	originalPoi := &OriginalPoi{}
-	originalPoi.setNext(minimumTimeInPoi)
+	ga.stopReason = StopIterations
+	for i := 0; i < ga.iterations; i++ {
+		if reason := check.stopReason(ctx, i, best.objectiveValue, ga.population); reason != "" {
+			ga.stopReason = reason
+			break
+		}
+		ga.evolve(i, replacement, &best)
+		if ga.alternatives != nil && ga.alternatives.Count > 0 {
+			start := time.Now()
+			ga.updateArchive()
+			ga.timePhase("archive", start)
+		}
+		ga.recordGeneration(i+1, ga.population)
+	}
+	return ga.result(best.itinerary), ga.Report()
+}
 
-	ga = &GeneticAlgorithm{
-		constraints:            originalPoi,
-		dayBeginHour:           dayBeginHour,
-		dayEndHour:             dayEndHour,
-		daysList:               daysList,
-		poiMultiplier:          poiMultiplier,
-		penaltyMultiplier:      penaltyMultiplier,
-		satisfactionMultiplier: satisfactionMultiplier,
+func (ga *GeneticAlgorithm) replacementStrategy() ReplacementStrategy {
+	if ga.replacement == nil {
+		return AgeBasedReplacement{TTL: ga.solutionTTL, Elitism: 1}
 	}
-	return ga
+	return ga.replacement
 }
 
-func (ga *GeneticAlgorithm) AddPoi(p *POI) {
-	ga.poiList = append(ga.poiList, p)
+// evolve creates the next generation, runs local search when it is due, updates the best solution found and
+// restarts the population if it converged.
+func (ga *GeneticAlgorithm) evolve(generation int, replacement ReplacementStrategy, best *solution) {
+	ga.nextGeneration(replacement)
+	if ga.localSearch != nil && ga.localSearch.TopK > 0 && ga.localSearch.Interval > 0 &&
+		(generation+1)%ga.localSearch.Interval == 0 {
+		start := time.Now()
+		ga.improveElite()
+		ga.timePhase("local-search", start)
+	}
+	if len(ga.population) > 0 && ga.population[0].objectiveValue > best.objectiveValue {
+		*best = ga.population[0]
+	}
+	ga.restartIfConverged()
 }
 
-func (ga *GeneticAlgorithm) selectParentsPairs(numberOfPairs int) [][]solution {
-	totalFitness := 0.0
-	for _, sol := range ga.population {
-		totalFitness += sol.objectiveValue
+func (ga *GeneticAlgorithm) nextGeneration(replacement ReplacementStrategy) {
+	start := time.Now()
+	offspring := ga.createOffspring(ga.selection, replacement.offspringCount(ga.populationSize, ga.random))
+	ga.timePhase("variation", start)
+
+	start = time.Now()
+	ga.assessSolutions(offspring)
+	ga.timePhase("evaluation", start)
+	ga.creditOperators(offspring)
+
+	start = time.Now()
+	ga.population = replacement.replace(ga.population, offspring, ga.populationSize, ga.random)
+	for i := range ga.population {
+		ga.population[i].age += 1
 	}
-	result := make([][]solution, len(ga.population))
-	for i := 0; i < numberOfPairs; i++ {
-		result[i] = make([]solution, 0)
-		for j := 0; j < 2; j++ {
-			// Generate a random value between 0 and the total fitness
-			randomValue := rand.Float64() * totalFitness
-
-			// Iterate through the solutions and accumulate fitness until the random value is reached
-			accumulatedFitness := 0.0
-			for _, sol := range ga.population {
-				accumulatedFitness += sol.objectiveValue
-				if accumulatedFitness >= randomValue {
-					result[i] = append(result[i], sol)
-					break
-				}
-			}
+	ga.timePhase("replacement", start)
+}
+
+func (ga *GeneticAlgorithm) creditOperators(offspring []solution) {
+	for _, sol := range offspring {
+		improved := sol.objectiveValue > sol.origin.parentObjective
+		ga.recordOperator("crossover", sol.origin.crossover, improved)
+		if sol.origin.mutation != "" {
+			ga.recordOperator("mutation", sol.origin.mutation, improved)
+			ga.mutation.update(sol.origin.mutation, improved)
 		}
 	}
-	return result
 }
 
-func (ga *GeneticAlgorithm) sortPopulation() {
-	sort.Slice(ga.population, func(i, j int) bool {
-		return ga.population[i].objectiveValue > ga.population[j].objectiveValue
-	})
+// SetCrossoverOperators sets the crossover operators, for every pair of parents one of them is chosen at random.
+// By default trips longer than one day use DayBoundaryCrossover and OrderCrossover, one day trips OrderCrossover.
+func (ga *GeneticAlgorithm) SetCrossoverOperators(operators ...CrossoverOperator) {
+	ga.crossoverOperators = operators
 }
 
-func (ga *GeneticAlgorithm) Run(initialPopulationSize int, iterations int, solutionTTL int) ApiItinerary {
-	ga.createInitialPopulation(initialPopulationSize)
-	rand.Seed(time.Now().UnixNano())
-	var solutionsToDelete []int
-	var numberOfParents int
-	bestObjectiveValue := -1000000.0
-
-	var bestItinerary Itinerary
-	//bestValuesSlice := make([]float64, iterations)
-
-	for i := 0; i < iterations; i++ {
-		if len(ga.daysList) > 1 {
-			numberOfParents = rand.Intn((initialPopulationSize/5)-(initialPopulationSize/10)) + (initialPopulationSize / 10) + 1
-			parents := ga.selectParentsPairs(numberOfParents)
-
-			solutionChan := make(chan solution, len(parents))
-			var wg sync.WaitGroup
-
-			for _, pair := range parents {
-				if len(pair) == 0 {
-					continue
-				}
-				wg.Add(1)
-				go func(pair []solution) {
-					defer wg.Done()
-					newItinerary1, newItinerary2 := CrossoverMultipleDays(&pair[0].itinerary, &pair[1].itinerary, ga.poiList)
-					newSolution1 := solution{
-						itinerary:      newItinerary1,
-						age:            0,
-						objectiveValue: 0.0,
-					}
-					newSolution2 := solution{
-						itinerary:      newItinerary2,
-						age:            0,
-						objectiveValue: 0.0,
-					}
-
-					if rand.Float64() < MUTATION_PROBABILITY {
-						substitutePOI(&newSolution1, ga.poiList, DAY_MUTATION_PROBABILITY)
-					}
-
-					if rand.Float64() < MUTATION_PROBABILITY {
-						substitutePOI(&newSolution2, ga.poiList, DAY_MUTATION_PROBABILITY)
-					}
-					solutionChan <- newSolution1
-					solutionChan <- newSolution2
-				}(pair)
-			}
-
-			go func() {
-				wg.Wait()
-				close(solutionChan)
-			}()
+// SetMutationSelection sets how mutation operators are chosen. By default all operators are used with adaptive
+// pursuit and an offspring is mutated with probability MUTATION_PROBABILITY.
+func (ga *GeneticAlgorithm) SetMutationSelection(selection MutationSelection) {
+	ga.mutation = selection
+}
 
-			for sol := range solutionChan {
-				ga.population = append(ga.population, sol)
-			}
+func (ga *GeneticAlgorithm) chooseCrossoverOperator() CrossoverOperator {
+	if len(ga.crossoverOperators) > 0 {
+		return ga.crossoverOperators[ga.random.Intn(len(ga.crossoverOperators))]
+	}
+	if len(ga.daysList) > 1 && ga.random.Intn(2) == 0 {
+		return DayBoundaryCrossover{}
+	}
+	return OrderCrossover{}
+}
 
-			solutionsToDelete = make([]int, 0)
-			for solId, sol := range ga.population {
-				if sol.age > solutionTTL {
-					solutionsToDelete = append(solutionsToDelete, solId)
-				}
-				ga.population[solId].age += 1
-			}
+// createOffspring creates children of parents chosen by selection with crossover and sometimes mutates them. Pairs
+// of parents are recombined in parallel, each with its own generator derived from the generator of the run.
+func (ga *GeneticAlgorithm) createOffspring(selection SelectionStrategy, count int) []solution {
+	if len(ga.population) == 0 || count <= 0 {
+		return nil
+	}
+	parents := selection.selectParentsPairs(ga.population, (count+1)/2, ga.random)
 
-			for _, idToDelete := range solutionsToDelete {
-				// delete too old solutions
-				if idToDelete < len(ga.population)-1 {
-					ga.population = append(ga.population[:idToDelete], ga.population[idToDelete+1:]...)
-				} else {
-					ga.population = ga.population[:idToDelete]
-				}
-			}
+	operators := make([]CrossoverOperator, len(parents))
+	mutations := make([][]MutationOperator, len(parents))
+	for i := range parents {
+		operators[i] = ga.chooseCrossoverOperator()
+		mutations[i] = []MutationOperator{ga.mutation.choose(ga.random), ga.mutation.choose(ga.random)}
+	}
+	randoms := splitRandom(ga.random, len(parents))
 
-			if len(ga.population) > initialPopulationSize {
-				// delete the weakest solution if populations is too large
-				ga.population = ga.population[:initialPopulationSize]
+	children := make([][]solution, len(parents))
+	parallelFor(len(parents), ga.workerCount(), func(i int) {
+		pair := parents[i]
+		if len(pair) < 2 {
+			return
+		}
+		parentObjective := pair[0].objectiveValue
+		if pair[1].objectiveValue > parentObjective {
+			parentObjective = pair[1].objectiveValue
+		}
+		newItinerary1, newItinerary2 := operators[i].crossover(&pair[0].itinerary, &pair[1].itinerary, ga.poiList,
+			randoms[i])
+		for j, itinerary := range []Itinerary{newItinerary1, newItinerary2} {
+			newSolution := solution{
+				itinerary:      itinerary,
+				age:            0,
+				objectiveValue: 0.0,
+				origin:         offspringOrigin{crossover: operators[i].name(), parentObjective: parentObjective},
 			}
-
-		} else {
-			var wg sync.WaitGroup
-			for i, _ := range ga.population {
-				wg.Add(1)
-				go func(data *solution) {
-					defer wg.Done()
-					substitutePOI(data, ga.poiList, 0.8)
-				}(&ga.population[i])
+			if mutations[i][j] != nil {
+				mutations[i][j].mutate(&newSolution, ga.poiList, randoms[i])
+				newSolution.origin.mutation = mutations[i][j].name()
 			}
-			go func() {
-				wg.Wait()
-			}()
+			children[i] = append(children[i], newSolution)
 		}
+	})
 
-		ga.assessPopulation()
-		ga.sortPopulation()
-
-		if i == 0 || ga.population[0].objectiveValue > bestObjectiveValue {
-			bestObjectiveValue = ga.population[0].objectiveValue
-			bestItinerary = ga.population[0].itinerary
-		}
-		//bestValuesSlice[i] = bestObjectiveValue
-		// last := len(ga.population) - 1
-		// fmt.Printf("Iteration %d\n Best: %f\n Worst: %f\n Best ever: %f\n Size: %d\n", i, ga.population[0].objectiveValue,
-		// 	ga.population[last].objectiveValue, bestObjectiveValue, len(ga.population))
+	offspring := make([]solution, 0, 2*len(parents))
+	for _, pairChildren := range children {
+		offspring = append(offspring, pairChildren...)
 	}
-
-	//saveValuesToFile(bestValuesSlice)
-	return convertToApiItinerary(&bestItinerary)
+	if len(offspring) > count {
+		offspring = offspring[:count]
+	}
+	return offspring
 }
 
 func (ga *GeneticAlgorithm) assessPopulation() {
-	for i, s := range ga.population {
+	ga.assessSolutions(ga.population)
+}
+
+func (ga *GeneticAlgorithm) assessSolutions(solutions []solution) {
+	for i, s := range solutions {
 		failedConstraints := ConstraintsCount{}
 		ga.constraints.execute(&s.itinerary, &failedConstraints)
 
-		objectiveFunction(&ga.population[i], failedConstraints.failedConstraints, ga.poiMultiplier, ga.penaltyMultiplier,
-			ga.satisfactionMultiplier)
+		objectiveFunction(&solutions[i], failedConstraints.failedConstraints, ga.poiMultiplier, ga.penaltyMultiplier,
+			ga.satisfactionMultiplier, ga.stabilityPenalty(&s.itinerary))
+		solutions[i].failedConstraints = failedConstraints.failedConstraints
+		ga.countConstraintFailures(&failedConstraints)
 	}
 }
 
@@ -262,34 +294,25 @@ func (ga *GeneticAlgorithm) assessPopulation() {
 //	objectiveFunction(s, failedConstraints.failedConstraints, ga.poiMultiplier, ga.penaltyMultiplier)
 //}
 
-func (ga *GeneticAlgorithm) createInitialPopulation(populationSize int) {
-	rand.Seed(time.Now().UnixNano())
-
-	solutionChan := make(chan solution, populationSize)
-	var wg sync.WaitGroup
-
-	ga.population = nil
-	for i := 0; i < populationSize; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			itinerary := GenerateRandomItinerary(ga.poiList, ga.dayBeginHour, ga.dayEndHour, ga.daysList)
-			newSolution := solution{
-				itinerary:      itinerary,
-				age:            0,
-				objectiveValue: 0.0,
-			}
-			solutionChan <- newSolution
-		}()
-	}
-	go func() {
-		wg.Wait()
-		close(solutionChan)
-	}()
-
-	ga.population = make([]solution, 0, populationSize)
-	for sol := range solutionChan {
-		ga.population = append(ga.population, sol)
-	}
+// createInitialPopulation creates random solutions, the first seeded of them with construction heuristics. All of
+// them keep the breaks set by SetBreaks and the locks set by SetLocks.
+func (ga *GeneticAlgorithm) createInitialPopulation(populationSize int, seeded int) {
+	ga.population = make([]solution, populationSize)
+	randoms := splitRandom(ga.random, populationSize)
+	parallelFor(populationSize, ga.workerCount(), func(i int) {
+		var itinerary Itinerary
+		if i < seeded {
+			itinerary = ga.constructSeed(i, randoms[i])
+		} else {
+			itinerary = GenerateRandomItinerary(ga.poiList, ga.dayBeginHour, ga.dayEndHour, ga.daysList, randoms[i])
+		}
+		ga.scheduleBreaks(&itinerary)
+		ga.locks.apply(&itinerary)
+		ga.population[i] = solution{
+			itinerary:      itinerary,
+			age:            0,
+			objectiveValue: 0.0,
+		}
+	})
 	ga.assessPopulation()
 }
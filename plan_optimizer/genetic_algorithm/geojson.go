@@ -0,0 +1,122 @@
+package genetic_algorithm
+
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+func geoJSONPosition(lat, lon float64) []float64 {
+	// GeoJSON positions are [longitude, latitude]
+	return []float64{lon, lat}
+}
+
+// ConvertToGeoJSON renders an itinerary as a FeatureCollection with a Point for every visit and a LineString for
+// every day. It works on the API representation, so it always shows exactly what /best-route returns.
+func ConvertToGeoJSON(itinerary ApiItinerary) GeoJSONFeatureCollection {
+	collection := GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]GeoJSONFeature, 0),
+	}
+
+	for _, day := range itinerary.Days {
+		for order, visit := range dayPoiVisits(day) {
+			collection.Features = append(collection.Features, GeoJSONFeature{
+				Type: "Feature",
+				Geometry: GeoJSONGeometry{
+					Type:        "Point",
+					Coordinates: geoJSONPosition(visit.Poi.Lat, visit.Poi.Lon),
+				},
+				Properties: map[string]interface{}{
+					"name":          visit.Poi.Name,
+					"day":           day.DayNumber,
+					"dayName":       day.DayName,
+					"order":         order + 1,
+					"start":         visit.StartVisit,
+					"end":           visit.EndVisit,
+					"visitDuration": visit.VisitDuration,
+					"satisfaction":  visit.Poi.Satisfaction,
+				},
+			})
+		}
+
+		points, distance, duration := dayRoute(day)
+		if len(points) < 2 {
+			continue
+		}
+		route := make([][]float64, len(points))
+		for i, point := range points {
+			route[i] = geoJSONPosition(point.Lat, point.Lon)
+		}
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONGeometry{
+				Type:        "LineString",
+				Coordinates: route,
+			},
+			Properties: map[string]interface{}{
+				"day":             day.DayNumber,
+				"dayName":         day.DayName,
+				"distanceMeters":  distance,
+				"durationMinutes": duration,
+			},
+		})
+	}
+	return collection
+}
+
+// dayPoiVisits returns the visits of POIs of the day without its breaks.
+func dayPoiVisits(day ApiDay) []ApiVisit {
+	visits := make([]ApiVisit, 0, len(day.Visits))
+	for _, visit := range day.Visits {
+		if visit.Type != breakType {
+			visits = append(visits, visit)
+		}
+	}
+	return visits
+}
+
+type routePoint struct {
+	Coordinate
+	Name string // set only for points where a visit or the accommodation is
+}
+
+// dayRoute joins the geometry of all legs of the day. Without legs the visits are connected with straight lines.
+func dayRoute(day ApiDay) (route []routePoint, distanceMeters float64, durationMinutes int) {
+	route = make([]routePoint, 0)
+	if len(day.Legs) == 0 {
+		for _, visit := range dayPoiVisits(day) {
+			route = append(route, routePoint{Coordinate: Coordinate{Lat: visit.Poi.Lat, Lon: visit.Poi.Lon}, Name: visit.Poi.Name})
+		}
+		return route, 0, 0
+	}
+	for _, leg := range day.Legs {
+		geometry := decodePolyline(leg.Polyline)
+		for i, c := range geometry {
+			if i == 0 && len(route) > 0 {
+				// the first point of a leg is the last point of the previous one
+				continue
+			}
+			point := routePoint{Coordinate: c}
+			if i == 0 {
+				point.Name = leg.From
+			} else if i == len(geometry)-1 {
+				point.Name = leg.To
+			}
+			route = append(route, point)
+		}
+		distanceMeters += leg.DistanceMeters
+		durationMinutes += leg.DurationMinutes
+	}
+	return route, distanceMeters, durationMinutes
+}
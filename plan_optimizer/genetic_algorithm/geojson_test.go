@@ -0,0 +1,58 @@
+package genetic_algorithm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// exportTestItinerary is a returned itinerary with legs and a lunch break taken in idle time.
+func exportTestItinerary(t *testing.T) ApiItinerary {
+	_, plan := warmStartTestProblem(t)
+	itinerary := convertToApiItinerary(&plan)
+	breaks := 0
+	for _, day := range itinerary.Days {
+		for _, visit := range day.Visits {
+			if visit.Type == breakType {
+				breaks++
+			}
+		}
+	}
+	if breaks == 0 || len(itinerary.Days[0].Legs) == 0 {
+		t.Fatalf("itinerary has %d breaks and %d legs on the first day", breaks, len(itinerary.Days[0].Legs))
+	}
+	return itinerary
+}
+
+// poiVisitNames returns the names of the visits of POIs in the itinerary.
+func poiVisitNames(itinerary ApiItinerary) []string {
+	names := make([]string, 0)
+	for _, day := range itinerary.Days {
+		for _, visit := range day.Visits {
+			if visit.Type == visitType {
+				names = append(names, visit.Poi.Name)
+			}
+		}
+	}
+	return names
+}
+
+func TestGeoJSONExcludesBreaks(t *testing.T) {
+	itinerary := exportTestItinerary(t)
+	collection := ConvertToGeoJSON(itinerary)
+	points := make([]string, 0)
+	lines := 0
+	for _, feature := range collection.Features {
+		switch feature.Geometry.Type {
+		case "Point":
+			points = append(points, feature.Properties["name"].(string))
+		case "LineString":
+			lines++
+		}
+	}
+	if expected := poiVisitNames(itinerary); !reflect.DeepEqual(points, expected) {
+		t.Errorf("points %v, expected the visits %v", points, expected)
+	}
+	if lines != len(itinerary.Days) {
+		t.Errorf("%d routes for %d days", lines, len(itinerary.Days))
+	}
+}
@@ -0,0 +1,80 @@
+package genetic_algorithm
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+type gpxDocument struct {
+	XMLName   xml.Name      `xml:"gpx"`
+	Version   string        `xml:"version,attr"`
+	Creator   string        `xml:"creator,attr"`
+	Namespace string        `xml:"xmlns,attr"`
+	Metadata  gpxMetadata   `xml:"metadata"`
+	Waypoints []gpxWaypoint `xml:"wpt"`
+	Routes    []gpxRoute    `xml:"rte"`
+}
+
+type gpxMetadata struct {
+	Name string `xml:"name"`
+}
+
+type gpxWaypoint struct {
+	Lat         float64 `xml:"lat,attr"`
+	Lon         float64 `xml:"lon,attr"`
+	Name        string  `xml:"name,omitempty"`
+	Description string  `xml:"desc,omitempty"`
+	Type        string  `xml:"type,omitempty"`
+}
+
+type gpxRoute struct {
+	Name   string        `xml:"name"`
+	Number int           `xml:"number"`
+	Points []gpxWaypoint `xml:"rtept"`
+}
+
+// ConvertToGPX renders an itinerary as GPX 1.1 with a waypoint for every visit and a route for every day. Route
+// points follow the leg geometry, so navigation apps do not have to guess the path between visits.
+func ConvertToGPX(itinerary ApiItinerary) ([]byte, error) {
+	document := gpxDocument{
+		Version:   "1.1",
+		Creator:   "plan_optimizer",
+		Namespace: "http://www.topografix.com/GPX/1/1",
+		Metadata:  gpxMetadata{Name: "Trip plan"},
+		Waypoints: make([]gpxWaypoint, 0),
+		Routes:    make([]gpxRoute, 0),
+	}
+
+	for _, day := range itinerary.Days {
+		for order, visit := range dayPoiVisits(day) {
+			document.Waypoints = append(document.Waypoints, gpxWaypoint{
+				Lat:  visit.Poi.Lat,
+				Lon:  visit.Poi.Lon,
+				Name: visit.Poi.Name,
+				Description: fmt.Sprintf("Day %d (%s), stop %d: %s-%s", day.DayNumber+1, day.DayName, order+1,
+					visit.StartVisit, visit.EndVisit),
+				Type: "visit",
+			})
+		}
+
+		points, _, _ := dayRoute(day)
+		if len(points) < 2 {
+			continue
+		}
+		route := gpxRoute{
+			Name:   fmt.Sprintf("Day %d (%s)", day.DayNumber+1, day.DayName),
+			Number: day.DayNumber + 1,
+			Points: make([]gpxWaypoint, len(points)),
+		}
+		for i, point := range points {
+			route.Points[i] = gpxWaypoint{Lat: point.Lat, Lon: point.Lon, Name: point.Name}
+		}
+		document.Routes = append(document.Routes, route)
+	}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,37 @@
+package genetic_algorithm
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestGPXExcludesBreaks(t *testing.T) {
+	itinerary := exportTestItinerary(t)
+	body, err := ConvertToGPX(itinerary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document gpxDocument
+	if err = xml.Unmarshal(body, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	waypoints := make([]string, len(document.Waypoints))
+	for i, waypoint := range document.Waypoints {
+		waypoints[i] = waypoint.Name
+	}
+	if expected := poiVisitNames(itinerary); !reflect.DeepEqual(waypoints, expected) {
+		t.Errorf("waypoints %v, expected the visits %v", waypoints, expected)
+	}
+	for _, route := range document.Routes {
+		for _, point := range route.Points {
+			if point.Name == lunch.Name {
+				t.Errorf("route %q passes the break", route.Name)
+			}
+		}
+	}
+	if len(document.Routes) != len(itinerary.Days) {
+		t.Errorf("%d routes for %d days", len(document.Routes), len(itinerary.Days))
+	}
+}
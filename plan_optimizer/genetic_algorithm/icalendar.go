@@ -0,0 +1,132 @@
+package genetic_algorithm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icalLineLimit = 75
+const icalTimeLayout = "20060102T150405Z"
+
+// ConvertToICalendar renders an itinerary as an RFC 5545 calendar with one VEVENT per visit. Every day is placed on
+// the first date with its day of the week which is not earlier than startDate and later than the previous day, so
+// the first day is on startDate if it is the same day of the week. Visit hours are interpreted in location and
+// written in UTC, so calendar applications show them correctly in any time zone.
+func ConvertToICalendar(itinerary ApiItinerary, startDate time.Time, location *time.Location) string {
+	var builder strings.Builder
+	writeLine := func(line string) {
+		builder.WriteString(foldICalLine(line))
+	}
+
+	stamp := time.Now().UTC().Format(icalTimeLayout)
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//plan_optimizer//Itinerary//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeICalText("Trip plan"))
+	writeLine("X-WR-TIMEZONE:" + location.String())
+
+	earliest := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, location)
+	for _, day := range itinerary.Days {
+		date := nextDateOnWeekday(earliest, day.DayName)
+		earliest = date.AddDate(0, 0, 1)
+		// visits after midnight are written with hours smaller than the previous ones, each such wrap moves the
+		// remaining visits to the next date
+		offset := 0
+		previous := 0
+		toDate := func(hour string) time.Time {
+			minutes := parseClockMinutes(hour)
+			for minutes+offset < previous {
+				offset += 24 * 60
+			}
+			previous = minutes + offset
+			return date.Add(time.Duration(minutes+offset) * time.Minute)
+		}
+
+		for order, visit := range day.Visits {
+			start := toDate(visit.StartVisit)
+			end := toDate(visit.EndVisit)
+
+			writeLine("BEGIN:VEVENT")
+			writeLine(fmt.Sprintf("UID:%s-%d-%d@plan_optimizer", date.Format("20060102"), day.DayNumber, order+1))
+			writeLine("DTSTAMP:" + stamp)
+			writeLine("DTSTART:" + start.UTC().Format(icalTimeLayout))
+			writeLine("DTEND:" + end.UTC().Format(icalTimeLayout))
+			writeLine("SUMMARY:" + escapeICalText(visit.Poi.Name))
+			writeLine("LOCATION:" + escapeICalText(visit.Poi.Name))
+			writeLine(fmt.Sprintf("GEO:%.6f;%.6f", visit.Poi.Lat, visit.Poi.Lon))
+			writeLine("DESCRIPTION:" + escapeICalText(visitDescription(day, order)))
+			writeLine("END:VEVENT")
+		}
+	}
+	writeLine("END:VCALENDAR")
+	return builder.String()
+}
+
+var icalWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// nextDateOnWeekday returns the first date from date on which is the day of the week with the given name. Unknown
+// names keep the date.
+func nextDateOnWeekday(date time.Time, dayName string) time.Time {
+	weekday, ok := icalWeekdays[dayName]
+	if !ok {
+		return date
+	}
+	return date.AddDate(0, 0, (int(weekday)-int(date.Weekday())+7)%7)
+}
+
+func parseClockMinutes(hour string) int {
+	t, err := time.Parse("15:04", hour)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// visitDescription tells how to get to the next stop of the day.
+func visitDescription(day ApiDay, order int) string {
+	visit := day.Visits[order]
+	for _, leg := range day.Legs {
+		if leg.From == visit.Poi.Name && leg.Departure == visit.EndVisit {
+			return fmt.Sprintf("Next: %s %.1f km (%d min) to %s, leave at %s, arrive at %s.",
+				leg.Mode, leg.DistanceMeters/1000.0, leg.DurationMinutes, leg.To, leg.Departure, leg.Arrival)
+		}
+	}
+	if order == len(day.Visits)-1 {
+		return "Last visit of the day."
+	}
+	return fmt.Sprintf("Next: %s at %s.", day.Visits[order+1].Poi.Name, day.Visits[order+1].StartVisit)
+}
+
+// escapeICalText escapes a TEXT value (RFC 5545, section 3.3.11).
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(text)
+}
+
+// foldICalLine splits a content line into lines of at most 75 octets (RFC 5545, section 3.1). Continuation lines
+// start with a space and multi-byte UTF-8 characters are never split.
+func foldICalLine(line string) string {
+	var builder strings.Builder
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		builder.WriteString(line[:cut])
+		builder.WriteString("\r\n ")
+		line = line[cut:]
+		// the leading space of a continuation line counts towards its length
+		limit = icalLineLimit - 1
+	}
+	builder.WriteString(line)
+	builder.WriteString("\r\n")
+	return builder.String()
+}
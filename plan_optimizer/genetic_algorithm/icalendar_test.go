@@ -0,0 +1,190 @@
+package genetic_algorithm
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func apiVisit(name, start, end string) ApiVisit {
+	return ApiVisit{Poi: ApiPOI{Name: name, Lat: 50.06, Lon: 19.94}, StartVisit: start, EndVisit: end, Type: visitType}
+}
+
+// unfoldICal joins folded lines and splits the calendar into content lines.
+func unfoldICal(t *testing.T, calendar string) []string {
+	if !strings.HasSuffix(calendar, "\r\n") {
+		t.Fatalf("calendar does not end with CRLF")
+	}
+	if strings.Contains(strings.ReplaceAll(calendar, "\r\n", ""), "\n") {
+		t.Fatalf("calendar has a line not ended with CRLF")
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(calendar, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+func eventProperties(lines []string) []map[string]string {
+	events := make([]map[string]string, 0)
+	var event map[string]string
+	for _, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = make(map[string]string)
+		case line == "END:VEVENT":
+			events = append(events, event)
+			event = nil
+		case event != nil:
+			name, value, _ := strings.Cut(line, ":")
+			event[name] = value
+		}
+	}
+	return events
+}
+
+func TestFoldICalLine(t *testing.T) {
+	tests := []string{
+		"SUMMARY:short",
+		"SUMMARY:" + strings.Repeat("a", 67),
+		"SUMMARY:" + strings.Repeat("a", 68),
+		"SUMMARY:" + strings.Repeat("a", 300),
+		"SUMMARY:" + strings.Repeat("Kraków ", 40),
+		"SUMMARY:" + strings.Repeat("ż", 100),
+		"SUMMARY:" + strings.Repeat("🏰", 50),
+	}
+	for _, line := range tests {
+		folded := foldICalLine(line)
+		if !strings.HasSuffix(folded, "\r\n") {
+			t.Errorf("folded line %q does not end with CRLF", folded)
+		}
+		parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+		for i, part := range parts {
+			if len(part) > icalLineLimit {
+				t.Errorf("line %d of %q has %d octets", i, line, len(part))
+			}
+			if i > 0 && !strings.HasPrefix(part, " ") {
+				t.Errorf("continuation line %q does not start with a space", part)
+			}
+			if !utf8.ValidString(part) {
+				t.Errorf("line %q splits a multi-byte character", part)
+			}
+		}
+		if unfolded := strings.ReplaceAll(strings.TrimSuffix(folded, "\r\n"), "\r\n ", ""); unfolded != line {
+			t.Errorf("unfolded line %q, want %q", unfolded, line)
+		}
+		if len(line) <= icalLineLimit && len(parts) != 1 {
+			t.Errorf("line of %d octets was folded", len(line))
+		}
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := map[string]string{
+		"Wawel":                  "Wawel",
+		"Cafe, bar; pub":         `Cafe\, bar\; pub`,
+		`C:\path`:                `C:\\path`,
+		"first\nsecond":          `first\nsecond`,
+		"first\r\nsecond":        `first\nsecond`,
+		`already \, escaped`:     `already \\\, escaped`,
+		"Sukiennice: hall, 1555": `Sukiennice: hall\, 1555`,
+	}
+	for text, want := range tests {
+		if escaped := escapeICalText(text); escaped != want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", text, escaped, want)
+		}
+	}
+}
+
+func TestConvertToICalendar(t *testing.T) {
+	itinerary := ApiItinerary{
+		DayBeginHour: "09:00",
+		DayEndHour:   "02:00",
+		Days: []ApiDay{
+			{DayNumber: 0, DayName: "mon", Visits: []ApiVisit{
+				apiVisit("Wawel, Royal Castle; Kraków", "09:00", "11:00"),
+				apiVisit(strings.Repeat("Very long name of a museum ", 5), "11:30", "13:00"),
+			}},
+			{DayNumber: 1, DayName: "wed", Visits: []ApiVisit{
+				apiVisit("Jazz club", "22:00", "23:30"),
+				apiVisit("Night bar", "23:45", "01:30"),
+			}},
+			{DayNumber: 2, DayName: "mon", Visits: []ApiVisit{apiVisit("Market", "10:00", "11:00")}},
+		},
+	}
+	location, err := time.LoadLocation("Europe/Warsaw")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	startDate := time.Date(2026, 10, 19, 0, 0, 0, 0, location) // a Monday
+	calendar := ConvertToICalendar(itinerary, startDate, location)
+	lines := unfoldICal(t, calendar)
+
+	if lines[0] != "BEGIN:VCALENDAR" || lines[len(lines)-1] != "END:VCALENDAR" {
+		t.Errorf("calendar is not a VCALENDAR: %q ... %q", lines[0], lines[len(lines)-1])
+	}
+	for _, required := range []string{"VERSION:2.0", "PRODID:-//plan_optimizer//Itinerary//EN"} {
+		found := false
+		for _, line := range lines {
+			found = found || line == required
+		}
+		if !found {
+			t.Errorf("calendar misses %q", required)
+		}
+	}
+
+	events := eventProperties(lines)
+	// Warsaw is UTC+2 until the last Sunday of October and UTC+1 after it
+	want := []struct{ start, end, summary string }{
+		{"20261019T070000Z", "20261019T090000Z", `Wawel\, Royal Castle\; Kraków`},
+		{"20261019T093000Z", "20261019T110000Z", strings.Repeat("Very long name of a museum ", 5)},
+		{"20261021T200000Z", "20261021T213000Z", "Jazz club"},
+		{"20261021T214500Z", "20261021T233000Z", "Night bar"},
+		{"20261026T090000Z", "20261026T100000Z", "Market"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	uids := make(map[string]bool)
+	for i, event := range events {
+		for _, property := range []string{"UID", "DTSTAMP", "DTSTART", "DTEND", "SUMMARY"} {
+			if event[property] == "" {
+				t.Errorf("event %d misses %s", i, property)
+			}
+		}
+		if uids[event["UID"]] {
+			t.Errorf("event %d repeats UID %s", i, event["UID"])
+		}
+		uids[event["UID"]] = true
+		if event["DTSTART"] != want[i].start || event["DTEND"] != want[i].end || event["SUMMARY"] != want[i].summary {
+			t.Errorf("event %d is %s-%s %q, want %s-%s %q", i, event["DTSTART"], event["DTEND"], event["SUMMARY"],
+				want[i].start, want[i].end, want[i].summary)
+		}
+	}
+}
+
+func TestConvertToICalendarPlacesDaysOnTheirWeekday(t *testing.T) {
+	tests := []struct {
+		startDate time.Time
+		days      []string
+		dates     []string
+	}{
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), []string{"mon", "wed"}, []string{"20261019", "20261021"}},
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), []string{"sat"}, []string{"20261024"}},
+		{time.Date(2026, 10, 24, 0, 0, 0, 0, time.UTC), []string{"sat", "sun", "mon"},
+			[]string{"20261024", "20261025", "20261026"}},
+		{time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), []string{"fri", "mon", "fri"},
+			[]string{"20261023", "20261026", "20261030"}},
+	}
+	for _, test := range tests {
+		itinerary := ApiItinerary{DayBeginHour: "09:00", DayEndHour: "19:00"}
+		for i, dayName := range test.days {
+			itinerary.Days = append(itinerary.Days, ApiDay{DayNumber: i, DayName: dayName,
+				Visits: []ApiVisit{apiVisit("Museum", "10:00", "12:00")}})
+		}
+		events := eventProperties(unfoldICal(t, ConvertToICalendar(itinerary, test.startDate, time.UTC)))
+		for i, event := range events {
+			if date := event["DTSTART"][:8]; date != test.dates[i] {
+				t.Errorf("days %v from %s: day %d is on %s, want %s", test.days, test.startDate.Format("Mon 2006-01-02"),
+					i, date, test.dates[i])
+			}
+		}
+	}
+}
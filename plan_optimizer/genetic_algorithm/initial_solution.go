@@ -5,8 +5,8 @@ import (
 	"time"
 )
 
-func GenerateRandomItinerary(allPoiList []*POI, dayStart time.Time, dayFinish time.Time, daysList []string) Itinerary {
-	rand.Seed(time.Now().UnixNano())
+func GenerateRandomItinerary(allPoiList []*POI, dayStart time.Time, dayFinish time.Time, daysList []string,
+	random *rand.Rand) Itinerary {
 	var startVisit time.Time
 	var endVisit time.Time
 
@@ -37,11 +37,11 @@ func GenerateRandomItinerary(allPoiList []*POI, dayStart time.Time, dayFinish ti
 
 		for {
 			// Break if no more available POIs or end time is reached
-			if len(poiForDay) == 0 || (prevVisit != nil && prevVisit.EndVisit.After(dayEndHour)) || (prevVisit != nil && addMinutes(prevVisit.EndVisit, 60).After(dayEndHour)) {
+			if len(poiForDay) == 0 || (prevVisit != nil && prevVisit.EndVisit.After(dayEndHour)) || (prevVisit != nil && addMinutes(prevVisit.EndVisit, minimumVisitDuration).After(dayEndHour)) {
 				break
 			}
 
-			newPoi, newPoiIndex := drawPoi(poiForDay)
+			newPoi, newPoiIndex := drawPoi(poiForDay, random)
 
 			// Calculate start and end times for the new POI
 			if prevVisit != nil {
@@ -54,8 +54,8 @@ func GenerateRandomItinerary(allPoiList []*POI, dayStart time.Time, dayFinish ti
 				timeDiff := calculateDuration(startVisit, newPoi.OpenHour[dayName])
 				startVisit = addMinutes(startVisit, timeDiff)
 			}
-			endVisit = minHour(addMinutes(startVisit, rand.Intn(121)+60), newPoi.CloseHour[dayName], dayEndHour)
-			if startVisit.After(endVisit) || startVisit.Equal(endVisit) || calculateDuration(startVisit, endVisit) < 60 {
+			endVisit = minHour(addMinutes(startVisit, random.Intn(121)+60), newPoi.CloseHour[dayName], dayEndHour)
+			if startVisit.After(endVisit) || startVisit.Equal(endVisit) || calculateDuration(startVisit, endVisit) < minimumVisitDuration {
 				poiForDay[newPoiIndex] = poiForDay[len(poiForDay)-1]
 				poiForDay = poiForDay[:len(poiForDay)-1]
 				continue
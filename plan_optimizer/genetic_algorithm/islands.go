@@ -0,0 +1,210 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// MigrationTopology decides to which islands the migrants of an island are sent.
+type MigrationTopology interface {
+	destinations(island int, islands int) []int
+}
+
+// RingTopology sends migrants of every island to the next one, the last island sends them to the first.
+type RingTopology struct{}
+
+func (RingTopology) destinations(island int, islands int) []int {
+	return []int{(island + 1) % islands}
+}
+
+// FullyConnectedTopology sends migrants of every island to all other islands.
+type FullyConnectedTopology struct{}
+
+func (FullyConnectedTopology) destinations(island int, islands int) []int {
+	destinations := make([]int, 0, islands-1)
+	for i := 0; i < islands; i++ {
+		if i != island {
+			destinations = append(destinations, i)
+		}
+	}
+	return destinations
+}
+
+// IslandModel splits the population into Islands sub-populations of equal size which evolve independently on a
+// pool of GOMAXPROCS goroutines. Every MigrationInterval generations copies of the best Migrants solutions of every
+// island are sent to the islands chosen by Topology, a ring by default, where the best of them replace the worst
+// solutions. The best solution of an island is never replaced by migrants. Islands start with the
+// settings of the algorithm and Configure, if set, may change them for every island, e.g. to use other selection,
+// crossover or mutation operators. Every island uses its own instance of adaptive mutation selection and its own
+// random number generator derived from the generator of the run.
+type IslandModel struct {
+	Islands           int
+	MigrationInterval int
+	Migrants          int
+	Topology          MigrationTopology
+	Configure         func(island int, ga *GeneticAlgorithm)
+}
+
+// SetIslandModel enables the island model, nil disables it.
+func (ga *GeneticAlgorithm) SetIslandModel(model *IslandModel) {
+	ga.islandModel = model
+}
+
+// island is a sub-population evolved by its own algorithm together with the best solution it has found.
+type island struct {
+	ga          *GeneticAlgorithm
+	replacement ReplacementStrategy
+	best        solution
+}
+
+func (ga *GeneticAlgorithm) createIsland(index int, populationSize int, random *rand.Rand) *island {
+	islandGa := &GeneticAlgorithm{
+		problem:            ga.problem,
+		selection:          ga.selection,
+		replacement:        ga.replacement,
+		crossoverOperators: ga.crossoverOperators,
+		mutation:           independentMutation(ga.mutation),
+		populationSize:     populationSize,
+		iterations:         ga.iterations,
+		solutionTTL:        ga.solutionTTL,
+		localSearch:        ga.localSearch,
+		restart:            ga.restart,
+		seeding:            ga.seeding,
+		warmStart:          ga.warmStart,
+		locks:              ga.locks,
+		workers:            1,
+	}
+	islandGa.random = random
+	islandGa.resetStats()
+	if ga.islandModel.Configure != nil {
+		ga.islandModel.Configure(index, islandGa)
+	}
+	return &island{ga: islandGa, replacement: islandGa.replacementStrategy()}
+}
+
+// independentMutation returns a mutation selection which can be used by another island at the same time.
+func independentMutation(selection MutationSelection) MutationSelection {
+	if adaptivePursuit, ok := selection.(*AdaptivePursuit); ok {
+		return CreateAdaptivePursuit(adaptivePursuit.operators, adaptivePursuit.rate)
+	}
+	return selection
+}
+
+func (ga *GeneticAlgorithm) solveIslands(ctx context.Context, check *terminationCheck) (ApiItinerary, RunReport) {
+	model := ga.islandModel
+	interval := model.MigrationInterval
+	if interval <= 0 {
+		interval = 10
+	}
+	islandSize := ga.populationSize / model.Islands
+	if islandSize < 2 {
+		islandSize = 2
+	}
+	workers := runtime.GOMAXPROCS(0)
+
+	start := time.Now()
+	islands := make([]*island, model.Islands)
+	randoms := splitRandom(ga.random, len(islands))
+	parallelFor(len(islands), workers, func(i int) {
+		islands[i] = ga.createIsland(i, islandSize, randoms[i])
+		islands[i].ga.createInitialPopulation(islandSize, islands[i].ga.seededCount(islandSize))
+		islands[i].ga.sortPopulation()
+		islands[i].best = solution{objectiveValue: -1000000.0}
+		if len(islands[i].ga.population) > 0 {
+			islands[i].best = islands[i].ga.population[0]
+		}
+	})
+	ga.gatherIslands(islands)
+	ga.timePhase("initialization", start)
+	ga.recordGeneration(0, ga.population)
+
+	// termination conditions are checked when the islands meet for migration
+	ga.stopReason = StopIterations
+	for generation := 0; generation < ga.iterations; generation += interval {
+		if reason := check.stopReason(ctx, generation, bestOfIslands(islands).objectiveValue,
+			ga.population); reason != "" {
+			ga.stopReason = reason
+			break
+		}
+		parallelFor(len(islands), workers, func(i int) {
+			for g := generation; g < generation+interval && g < ga.iterations && ctx.Err() == nil; g++ {
+				islands[i].ga.evolve(g, islands[i].replacement, &islands[i].best)
+			}
+		})
+		start := time.Now()
+		ga.migrate(islands)
+		ga.gatherIslands(islands)
+		ga.timePhase("migration", start)
+		if ga.alternatives != nil && ga.alternatives.Count > 0 {
+			start = time.Now()
+			ga.updateArchive()
+			ga.timePhase("archive", start)
+		}
+		generationsDone := generation + interval
+		if generationsDone > ga.iterations {
+			generationsDone = ga.iterations
+		}
+		ga.recordGeneration(generationsDone, ga.population)
+	}
+
+	for _, isl := range islands {
+		ga.mergeStats(&isl.ga.problem)
+	}
+	return ga.result(bestOfIslands(islands).itinerary), ga.Report()
+}
+
+func bestOfIslands(islands []*island) solution {
+	best := islands[0].best
+	for _, isl := range islands {
+		if isl.best.objectiveValue > best.objectiveValue {
+			best = isl.best
+		}
+	}
+	return best
+}
+
+// migrate sends copies of the best solutions of every island to its destinations, where the best of them replace
+// the worst solutions except the best one. All migrants are chosen before any island is changed.
+func (ga *GeneticAlgorithm) migrate(islands []*island) {
+	topology := ga.islandModel.Topology
+	if topology == nil {
+		topology = RingTopology{}
+	}
+	incoming := make([][]solution, len(islands))
+	for i, isl := range islands {
+		migrants := ga.islandModel.Migrants
+		if migrants > len(isl.ga.population) {
+			migrants = len(isl.ga.population)
+		}
+		for _, destination := range topology.destinations(i, len(islands)) {
+			for _, migrant := range isl.ga.population[:migrants] {
+				migrant.itinerary = copyItinerary(&migrant.itinerary)
+				migrant.age = 0
+				incoming[destination] = append(incoming[destination], migrant)
+			}
+		}
+	}
+	for i, isl := range islands {
+		population := isl.ga.population
+		sortSolutions(incoming[i])
+		replaced := len(population) - 1
+		if replaced > len(incoming[i]) {
+			replaced = len(incoming[i])
+		}
+		for j := 0; j < replaced; j++ {
+			population[len(population)-1-j] = incoming[i][j]
+		}
+		isl.ga.sortPopulation()
+	}
+}
+
+// gatherIslands sets the population of the algorithm to the sorted union of populations of all islands.
+func (ga *GeneticAlgorithm) gatherIslands(islands []*island) {
+	ga.population = nil
+	for _, isl := range islands {
+		ga.population = mergeSolutions(ga.population, isl.ga.population)
+	}
+	ga.sortPopulation()
+}
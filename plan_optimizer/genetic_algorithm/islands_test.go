@@ -0,0 +1,148 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// testIslands creates islands of the given size with objective values from 10*island + size down to 10*island + 1.
+func testIslands(count int, size int) []*island {
+	islands := make([]*island, count)
+	for i := range islands {
+		population := make([]solution, size)
+		for j := range population {
+			population[j] = solution{objectiveValue: float64(10*i + size - j), age: 5}
+		}
+		islands[i] = &island{ga: &GeneticAlgorithm{population: population}}
+	}
+	return islands
+}
+
+func islandObjectives(isl *island) []float64 {
+	objectives := make([]float64, len(isl.ga.population))
+	for i, sol := range isl.ga.population {
+		objectives[i] = sol.objectiveValue
+	}
+	return objectives
+}
+
+func TestRingMigration(t *testing.T) {
+	geneticAlgorithm := &GeneticAlgorithm{islandModel: &IslandModel{Islands: 4, Migrants: 2}}
+	islands := testIslands(4, 5)
+	geneticAlgorithm.migrate(islands)
+	for i, isl := range islands {
+		// the two best solutions of the previous island replace the two worst ones
+		from := 10 * ((i + 3) % 4)
+		expected := []float64{float64(10*i + 5), float64(10*i + 4), float64(10*i + 3), float64(from + 5),
+			float64(from + 4)}
+		objectives := islandObjectives(isl)
+		if !sameObjectiveSets(objectives, expected) {
+			t.Errorf("island %d has objectives %v after migration, expected %v", i, objectives, expected)
+		}
+		for _, sol := range isl.ga.population {
+			if sol.objectiveValue == float64(from+5) && sol.age != 0 {
+				t.Errorf("migrant to island %d has age %d", i, sol.age)
+			}
+		}
+	}
+}
+
+func TestFullyConnectedMigrationKeepsBestOfIsland(t *testing.T) {
+	// 99 islands send 2 migrants each to islands of 3 solutions
+	geneticAlgorithm := &GeneticAlgorithm{islandModel: &IslandModel{Islands: 100, Migrants: 2,
+		Topology: FullyConnectedTopology{}}}
+	islands := testIslands(100, 3)
+	geneticAlgorithm.migrate(islands)
+	for i, isl := range islands {
+		objectives := islandObjectives(isl)
+		// the best migrants come from the last island, or from the one before it for the last island
+		best := 993.0
+		if i == 99 {
+			best = 983.0
+		}
+		expected := []float64{float64(10*i + 3), best, best - 1}
+		if !sameObjectiveSets(objectives, expected) {
+			t.Errorf("island %d has objectives %v after migration, expected %v", i, objectives, expected)
+		}
+	}
+}
+
+// sameObjectiveSets tells if two lists hold the same objective values in any order.
+func sameObjectiveSets(a, b []float64) bool {
+	count := make(map[float64]int)
+	for _, value := range a {
+		count[value]++
+	}
+	for _, value := range b {
+		count[value]--
+	}
+	for _, c := range count {
+		if c != 0 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestIslandModelConfiguresEveryIsland(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(9)), 20)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	geneticAlgorithm.SetRunParameters(40, 10, 8)
+	var mutex sync.Mutex
+	configured := make([]bool, 4)
+	geneticAlgorithm.SetIslandModel(&IslandModel{Islands: 4, MigrationInterval: 5, Migrants: 1,
+		Topology: FullyConnectedTopology{},
+		Configure: func(island int, ga *GeneticAlgorithm) {
+			mutex.Lock()
+			configured[island] = true
+			mutex.Unlock()
+			if island == 0 {
+				ga.SetCrossoverOperators(PartiallyMappedCrossover{})
+			}
+		}})
+	_, report := geneticAlgorithm.Solve(context.Background())
+	if !reflect.DeepEqual(configured, []bool{true, true, true, true}) {
+		t.Errorf("configured islands %v", configured)
+	}
+	applied := false
+	for _, stats := range report.Operators {
+		if stats.Name == "pmx" && stats.Applied > 0 {
+			applied = true
+		}
+	}
+	if !applied {
+		t.Errorf("crossover operator of the first island was not used")
+	}
+}
+
+// benchmarkSolve runs the genetic algorithm with the default settings and a fixed seed on a fixed instance of 80
+// POIs and 2 days.
+func benchmarkSolve(b *testing.B, configure func(ga *GeneticAlgorithm)) {
+	pois := randomPois(rand.New(rand.NewSource(6)), 80)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+		for _, poi := range pois {
+			geneticAlgorithm.AddPoi(poi)
+		}
+		geneticAlgorithm.SetSeed(1)
+		configure(geneticAlgorithm)
+		geneticAlgorithm.Solve(context.Background())
+	}
+}
+
+func BenchmarkSolve(b *testing.B) {
+	benchmarkSolve(b, func(ga *GeneticAlgorithm) {})
+}
+
+func BenchmarkSolveIslands(b *testing.B) {
+	benchmarkSolve(b, func(ga *GeneticAlgorithm) {
+		ga.SetIslandModel(&IslandModel{Islands: 4, MigrationInterval: 10, Migrants: 2})
+	})
+}
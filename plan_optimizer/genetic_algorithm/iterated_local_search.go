@@ -0,0 +1,68 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"time"
+)
+
+// IteratedLocalSearch improves a random itinerary with the local search moves of LocalSearch, then repeatedly
+// perturbs the current itinerary with a few random mutations and improves it again. The perturbed itinerary
+// replaces the current one if it is not worse.
+type IteratedLocalSearch struct {
+	problem
+	iterations           int
+	perturbationStrength int
+	maxPasses            int
+}
+
+func CreateIteratedLocalSearch(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) *IteratedLocalSearch {
+	return &IteratedLocalSearch{
+		problem: createProblem(dayBeginHour, dayEndHour, daysList, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier),
+		iterations:           100,
+		perturbationStrength: 3,
+		maxPasses:            3,
+	}
+}
+
+// SetIterations sets the number of perturbations, 100 by default.
+func (ils *IteratedLocalSearch) SetIterations(iterations int) {
+	ils.iterations = iterations
+}
+
+// SetPerturbationStrength sets how many random mutations are applied in a perturbation, 3 by default.
+func (ils *IteratedLocalSearch) SetPerturbationStrength(strength int) {
+	ils.perturbationStrength = strength
+}
+
+func (ils *IteratedLocalSearch) Solve(ctx context.Context) (ApiItinerary, RunReport) {
+	ils.startRun()
+	operators := AllMutationOperators()
+
+	initial := ils.randomSolution()
+	current := solution{}
+	current.itinerary, current.objectiveValue, _ = ils.improveItinerary(&initial.itinerary, ils.maxPasses)
+	best := current
+
+	for i := 0; i < ils.iterations && ctx.Err() == nil; i++ {
+		candidate := solution{itinerary: copyItinerary(&current.itinerary)}
+		for j := 0; j < ils.perturbationStrength; j++ {
+			operators[ils.random.Intn(len(operators))].mutate(&candidate, ils.poiList, ils.random)
+		}
+		itinerary, objectiveValue, improvedMoves := ils.improveItinerary(&candidate.itinerary, ils.maxPasses)
+		for _, move := range localSearchMoves {
+			ils.recordOperator("local-search", move.name, improvedMoves[move.name])
+		}
+		ils.recordOperator("perturbation", "random", objectiveValue > current.objectiveValue)
+
+		if objectiveValue >= current.objectiveValue {
+			current = solution{itinerary: itinerary, objectiveValue: objectiveValue}
+		}
+		if current.objectiveValue > best.objectiveValue {
+			best = current
+		}
+	}
+	ils.stopReason = iterationsStopReason(ctx)
+	return ils.result(best.itinerary), ils.Report()
+}
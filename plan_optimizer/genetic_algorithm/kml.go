@@ -0,0 +1,104 @@
+package genetic_algorithm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// KML colors are aabbggrr, each day gets the next color from the list.
+var kmlDayColors = []string{"ff0000ff", "ffff0000", "ff00aa00", "ff00a5ff", "ff800080", "ffffff00", "ff0080ff"}
+
+type kmlDocument struct {
+	XMLName   xml.Name     `xml:"kml"`
+	Namespace string       `xml:"xmlns,attr"`
+	Document  kmlContainer `xml:"Document"`
+}
+
+type kmlContainer struct {
+	Name       string         `xml:"name"`
+	Styles     []kmlStyle     `xml:"Style,omitempty"`
+	Folders    []kmlContainer `xml:"Folder,omitempty"`
+	Placemarks []kmlPlacemark `xml:"Placemark,omitempty"`
+}
+
+type kmlStyle struct {
+	Id        string       `xml:"id,attr"`
+	LineStyle kmlLineStyle `xml:"LineStyle"`
+}
+
+type kmlLineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+type kmlPlacemark struct {
+	Name        string         `xml:"name"`
+	Description string         `xml:"description,omitempty"`
+	StyleUrl    string         `xml:"styleUrl,omitempty"`
+	Point       *kmlPoint      `xml:"Point,omitempty"`
+	LineString  *kmlLineString `xml:"LineString,omitempty"`
+}
+
+type kmlPoint struct {
+	Coordinates string `xml:"coordinates"`
+}
+
+type kmlLineString struct {
+	Tessellate  int    `xml:"tessellate"`
+	Coordinates string `xml:"coordinates"`
+}
+
+func kmlCoordinate(lat, lon float64) string {
+	return fmt.Sprintf("%f,%f", lon, lat)
+}
+
+// ConvertToKML renders an itinerary as KML 2.2 with one folder per day. Each folder holds a placemark for every
+// visit and a line with the route of the day, drawn in a color of that day.
+func ConvertToKML(itinerary ApiItinerary) ([]byte, error) {
+	document := kmlDocument{
+		Namespace: "http://www.opengis.net/kml/2.2",
+		Document:  kmlContainer{Name: "Trip plan"},
+	}
+
+	for _, day := range itinerary.Days {
+		styleId := fmt.Sprintf("day-%d", day.DayNumber+1)
+		document.Document.Styles = append(document.Document.Styles, kmlStyle{
+			Id:        styleId,
+			LineStyle: kmlLineStyle{Color: kmlDayColors[day.DayNumber%len(kmlDayColors)], Width: 4},
+		})
+
+		folder := kmlContainer{Name: fmt.Sprintf("Day %d (%s)", day.DayNumber+1, day.DayName)}
+		for order, visit := range dayPoiVisits(day) {
+			folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+				Name:        fmt.Sprintf("%d. %s", order+1, visit.Poi.Name),
+				Description: fmt.Sprintf("%s-%s", visit.StartVisit, visit.EndVisit),
+				Point:       &kmlPoint{Coordinates: kmlCoordinate(visit.Poi.Lat, visit.Poi.Lon)},
+			})
+		}
+
+		points, distance, duration := dayRoute(day)
+		if len(points) >= 2 {
+			coordinates := make([]string, len(points))
+			for i, point := range points {
+				coordinates[i] = kmlCoordinate(point.Lat, point.Lon)
+			}
+			placemark := kmlPlacemark{
+				Name:       "Route",
+				StyleUrl:   "#" + styleId,
+				LineString: &kmlLineString{Tessellate: 1, Coordinates: strings.Join(coordinates, " ")},
+			}
+			if len(day.Legs) > 0 {
+				placemark.Description = fmt.Sprintf("%.1f km, %d min", distance/1000.0, duration)
+			}
+			folder.Placemarks = append(folder.Placemarks, placemark)
+		}
+		document.Document.Folders = append(document.Document.Folders, folder)
+	}
+
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,39 @@
+package genetic_algorithm
+
+import (
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestKMLExcludesBreaks(t *testing.T) {
+	itinerary := exportTestItinerary(t)
+	body, err := ConvertToKML(itinerary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document kmlDocument
+	if err = xml.Unmarshal(body, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	visits := make([]string, 0)
+	routes := 0
+	for _, folder := range document.Document.Folders {
+		for _, placemark := range folder.Placemarks {
+			if placemark.LineString != nil {
+				routes++
+				continue
+			}
+			// visit placemarks are named with their order in the day
+			visits = append(visits, placemark.Name[strings.Index(placemark.Name, ". ")+2:])
+		}
+	}
+	if expected := poiVisitNames(itinerary); !reflect.DeepEqual(visits, expected) {
+		t.Errorf("placemarks %v, expected the visits %v", visits, expected)
+	}
+	if len(document.Document.Folders) != len(itinerary.Days) || routes != len(itinerary.Days) {
+		t.Errorf("%d folders and %d routes for %d days", len(document.Document.Folders), routes, len(itinerary.Days))
+	}
+}
@@ -0,0 +1,267 @@
+package genetic_algorithm
+
+import (
+	"sort"
+	"sync"
+)
+
+const localSearchEpsilon = 1e-9
+
+// LocalSearch configures the optional memetic phase: every Interval iterations the best TopK solutions are
+// improved with intra-day 2-opt and or-opt, best insertion of unused POIs and swaps with unused POIs of higher
+// satisfaction. Days are scheduled with decodeDay and a move is accepted only if it does not fail more constraints
+// and improves the objective, or keeps it and shortens the travel time of the day. MaxPasses limits how many times
+// all moves are repeated on one solution, 3 by default.
+type LocalSearch struct {
+	TopK      int
+	Interval  int
+	MaxPasses int
+}
+
+// SetLocalSearch enables local search on the best solutions, nil disables it.
+func (ga *GeneticAlgorithm) SetLocalSearch(localSearch *LocalSearch) {
+	ga.localSearch = localSearch
+}
+
+type localSearchMove struct {
+	name  string
+	apply func(search *localSearchRun) bool
+}
+
+var localSearchMoves = []localSearchMove{
+	{name: "2-opt", apply: (*localSearchRun).twoOpt},
+	{name: "or-opt", apply: (*localSearchRun).orOpt},
+	{name: "insertion", apply: (*localSearchRun).bestInsertion},
+	{name: "swap", apply: (*localSearchRun).swapUnused},
+}
+
+// localSearchRun holds the itinerary being improved together with its current evaluation.
+type localSearchRun struct {
+	problem           *problem
+	itinerary         Itinerary
+	objectiveValue    float64
+	failedConstraints int
+	improvedMoves     map[string]bool
+}
+
+// improveElite runs local search on the best solutions of the population in parallel and sorts the population.
+func (ga *GeneticAlgorithm) improveElite() {
+	topK := ga.localSearch.TopK
+	if topK > len(ga.population) {
+		topK = len(ga.population)
+	}
+	maxPasses := ga.localSearch.MaxPasses
+	if maxPasses <= 0 {
+		maxPasses = 3
+	}
+
+	improvedMoves := make([]map[string]bool, topK)
+	var wg sync.WaitGroup
+	for i := 0; i < topK; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sol := &ga.population[i]
+			itinerary, objectiveValue, improved := ga.improveItinerary(&sol.itinerary, maxPasses)
+			if objectiveValue > sol.objectiveValue {
+				sol.itinerary = itinerary
+				sol.objectiveValue, sol.failedConstraints = ga.evaluateItinerary(&itinerary)
+			}
+			improvedMoves[i] = improved
+		}(i)
+	}
+	wg.Wait()
+
+	for _, improved := range improvedMoves {
+		for _, move := range localSearchMoves {
+			ga.recordOperator("local-search", move.name, improved[move.name])
+		}
+	}
+	ga.sortPopulation()
+}
+
+// improveItinerary repeats all local search moves on a copy of the itinerary until none of them improves it or
+// maxPasses is reached. It returns the improved itinerary, its objective value and the moves which improved it.
+func (p *problem) improveItinerary(itinerary *Itinerary, maxPasses int) (Itinerary, float64, map[string]bool) {
+	search := &localSearchRun{
+		problem:       p,
+		itinerary:     copyItinerary(itinerary),
+		improvedMoves: make(map[string]bool),
+	}
+	search.objectiveValue, search.failedConstraints = p.evaluateItinerary(&search.itinerary)
+
+	for pass := 0; pass < maxPasses; pass++ {
+		improved := false
+		for _, move := range localSearchMoves {
+			if move.apply(search) {
+				improved = true
+				search.improvedMoves[move.name] = true
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return search.itinerary, search.objectiveValue, search.improvedMoves
+}
+
+func dayTravelTime(day Day) int {
+	travelTime := 0
+	for i := 1; i < len(day.Visits); i++ {
+		travelTime += transport(day.Visits[i-1].Poi, day.Visits[i].Poi)
+	}
+	return travelTime
+}
+
+// evaluateDay schedules the visits in place of the day with the given index and returns the scheduled day and the
+// objective value of the whole itinerary. The itinerary itself is left unchanged. ok is false if the day is locked,
+// a visit had to be dropped or more constraints failed.
+func (search *localSearchRun) evaluateDay(dayId int, visits []Visit) (candidate Day, objectiveValue float64, ok bool) {
+	day := &search.itinerary.Days[dayId]
+	original := day.Visits
+	candidate = day.withoutVisits()
+	if search.itinerary.locks.dayLocked(dayId) {
+		return candidate, 0, false
+	}
+	if !tryDaySchedule(&candidate, visits, search.itinerary.DayBeginHour, search.itinerary.DayEndHour) {
+		return candidate, 0, false
+	}
+	day.Visits = candidate.Visits
+	objectiveValue, failedConstraints := search.problem.evaluateItinerary(&search.itinerary)
+	day.Visits = original
+	return candidate, objectiveValue, failedConstraints <= search.failedConstraints
+}
+
+// tryDay replaces the visits of the day if the move is accepted.
+func (search *localSearchRun) tryDay(dayId int, visits []Visit) bool {
+	candidate, objectiveValue, ok := search.evaluateDay(dayId, visits)
+	if !ok || objectiveValue < search.objectiveValue-localSearchEpsilon {
+		return false
+	}
+	if objectiveValue <= search.objectiveValue+localSearchEpsilon &&
+		dayTravelTime(candidate) >= dayTravelTime(search.itinerary.Days[dayId]) {
+		return false
+	}
+	search.accept(dayId, candidate)
+	return true
+}
+
+func (search *localSearchRun) accept(dayId int, day Day) {
+	search.itinerary.Days[dayId] = day
+	search.objectiveValue, search.failedConstraints = search.problem.evaluateItinerary(&search.itinerary)
+}
+
+// twoOpt reverses parts of a day, which removes crossing walks.
+func (search *localSearchRun) twoOpt() bool {
+	improved := false
+	for dayId := range search.itinerary.Days {
+		for i := 0; i < len(search.itinerary.Days[dayId].Visits)-1; i++ {
+			for j := i + 1; j < len(search.itinerary.Days[dayId].Visits); j++ {
+				visits := copyVisits(search.itinerary.Days[dayId].Visits)
+				for a, b := i, j; a < b; a, b = a+1, b-1 {
+					visits[a], visits[b] = visits[b], visits[a]
+				}
+				if search.tryDay(dayId, visits) {
+					improved = true
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// orOpt moves segments of one to three consecutive visits to another position of the same day.
+func (search *localSearchRun) orOpt() bool {
+	improved := false
+	for dayId := range search.itinerary.Days {
+		for length := 1; length <= 3; length++ {
+			for i := 0; i+length <= len(search.itinerary.Days[dayId].Visits); i++ {
+				visits := search.itinerary.Days[dayId].Visits
+				segment := copyVisits(visits[i : i+length])
+				rest := append(copyVisits(visits[:i]), visits[i+length:]...)
+				for position := 0; position <= len(rest); position++ {
+					if position == i {
+						continue
+					}
+					candidate := make([]Visit, 0, len(visits))
+					candidate = append(candidate, rest[:position]...)
+					candidate = append(candidate, segment...)
+					candidate = append(candidate, rest[position:]...)
+					if search.tryDay(dayId, candidate) {
+						improved = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return improved
+}
+
+// unusedPois returns POIs which are not visited, the ones with the highest satisfaction first.
+func (search *localSearchRun) unusedPois() []*POI {
+	unused := filterUnusedPois(&solution{itinerary: search.itinerary}, search.problem.poiList)
+	sort.SliceStable(unused, func(i, j int) bool {
+		return unused[i].Satisfaction > unused[j].Satisfaction
+	})
+	return unused
+}
+
+// bestInsertion puts unused POIs at the position of the itinerary where they improve the objective the most.
+func (search *localSearchRun) bestInsertion() bool {
+	improved := false
+	for _, poi := range search.unusedPois() {
+		bestDayId := -1
+		var bestDay Day
+		bestValue := search.objectiveValue + localSearchEpsilon
+		for dayId, day := range search.itinerary.Days {
+			// skip days when the POI is not open long enough
+			if calculateDuration(maxHour(search.itinerary.DayBeginHour, poi.OpenHour[day.DayName]),
+				minHour(search.itinerary.DayEndHour, poi.CloseHour[day.DayName])) < minimumVisitDuration {
+				continue
+			}
+			for position := 0; position <= len(day.Visits); position++ {
+				visits := make([]Visit, 0, len(day.Visits)+1)
+				visits = append(visits, day.Visits[:position]...)
+				visits = append(visits, Visit{Poi: poi, VisitDuration: defaultVisitDuration})
+				visits = append(visits, day.Visits[position:]...)
+				candidate, objectiveValue, ok := search.evaluateDay(dayId, visits)
+				if ok && objectiveValue > bestValue {
+					bestDayId, bestDay, bestValue = dayId, candidate, objectiveValue
+				}
+			}
+		}
+		if bestDayId >= 0 {
+			search.accept(bestDayId, bestDay)
+			improved = true
+		}
+	}
+	return improved
+}
+
+// swapUnused replaces visits with unused POIs of higher satisfaction, keeping the visit duration.
+func (search *localSearchRun) swapUnused() bool {
+	improved := false
+	unused := search.unusedPois()
+	for dayId := range search.itinerary.Days {
+		for visitId := range search.itinerary.Days[dayId].Visits {
+			current := search.itinerary.Days[dayId].Visits[visitId].Poi
+			for i, poi := range unused {
+				if poi.Satisfaction <= current.Satisfaction {
+					break
+				}
+				visits := copyVisits(search.itinerary.Days[dayId].Visits)
+				visits[visitId].Poi = poi
+				if search.tryDay(dayId, visits) {
+					unused[i] = current
+					sort.SliceStable(unused, func(i, j int) bool {
+						return unused[i].Satisfaction > unused[j].Satisfaction
+					})
+					improved = true
+					break
+				}
+			}
+		}
+	}
+	return improved
+}
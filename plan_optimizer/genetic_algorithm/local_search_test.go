@@ -0,0 +1,104 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func localSearchTestProblem(pois []*POI) *GeneticAlgorithm {
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	return geneticAlgorithm
+}
+
+func TestTwoOptUntanglesZigZag(t *testing.T) {
+	// four POIs a kilometer apart on a line, visited back and forth
+	pois := breakTestPois(4)
+	for i, poi := range pois {
+		poi.Lat, poi.Lon, poi.Satisfaction = 50.05, 19.90+0.014*float64(i), 5
+	}
+	geneticAlgorithm := localSearchTestProblem(pois)
+	durations := map[*POI]int{pois[0]: 60, pois[1]: 60, pois[2]: 60, pois[3]: 60}
+	zigZag := []*POI{pois[0], pois[2], pois[1], pois[3]}
+	search := &localSearchRun{
+		problem:       &geneticAlgorithm.problem,
+		itinerary:     decodeItinerary([][]*POI{zigZag}, durations, clock(9*60), clock(19*60), testDays),
+		improvedMoves: make(map[string]bool),
+	}
+	search.objectiveValue, search.failedConstraints = geneticAlgorithm.evaluateItinerary(&search.itinerary)
+	travelTime := dayTravelTime(search.itinerary.Days[0])
+
+	if !search.twoOpt() {
+		t.Fatalf("2-opt did not improve the zig-zag day")
+	}
+	sequence := daySequence(search.itinerary.Days[0])
+	reversed := []*POI{pois[3], pois[2], pois[1], pois[0]}
+	if !reflect.DeepEqual(sequence, pois) && !reflect.DeepEqual(sequence, reversed) {
+		t.Errorf("2-opt left the day in order %v", sequence)
+	}
+	if dayTravelTime(search.itinerary.Days[0]) >= travelTime {
+		t.Errorf("travel time %d is not shorter than %d", dayTravelTime(search.itinerary.Days[0]), travelTime)
+	}
+}
+
+func TestLocalSearchMovesNeverWorsen(t *testing.T) {
+	random := rand.New(rand.NewSource(20))
+	pois := randomPois(random, 30)
+	geneticAlgorithm := localSearchTestProblem(pois)
+
+	for i := 0; i < 30; i++ {
+		shuffled := append([]*POI(nil), pois...)
+		random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		itinerary := decodeItinerary([][]*POI{shuffled[:4], shuffled[4:8]}, nil, clock(9*60), clock(19*60), testDays)
+		for _, move := range localSearchMoves {
+			search := &localSearchRun{
+				problem:       &geneticAlgorithm.problem,
+				itinerary:     copyItinerary(&itinerary),
+				improvedMoves: make(map[string]bool),
+			}
+			search.objectiveValue, search.failedConstraints = geneticAlgorithm.evaluateItinerary(&search.itinerary)
+			for {
+				before := search.objectiveValue
+				if !move.apply(search) {
+					break
+				}
+				objectiveValue, failedConstraints := geneticAlgorithm.evaluateItinerary(&search.itinerary)
+				if failedConstraints > 0 {
+					t.Fatalf("%s broke %d constraints", move.name, failedConstraints)
+				}
+				if objectiveValue < before-localSearchEpsilon {
+					t.Fatalf("%s lowered the objective from %f to %f", move.name, before, objectiveValue)
+				}
+			}
+		}
+	}
+}
+
+func TestImproveEliteKeepsBetterSolutions(t *testing.T) {
+	random := rand.New(rand.NewSource(21))
+	pois := randomPois(random, 30)
+	geneticAlgorithm := localSearchTestProblem(pois)
+	geneticAlgorithm.SetRunParameters(10, 1, 4)
+	geneticAlgorithm.SetLocalSearch(&LocalSearch{TopK: 4})
+	geneticAlgorithm.startRun()
+	geneticAlgorithm.createInitialPopulation(10, 0)
+	geneticAlgorithm.sortPopulation()
+	before := make([]float64, 4)
+	for i := range before {
+		before[i] = geneticAlgorithm.population[i].objectiveValue
+	}
+
+	geneticAlgorithm.improveElite()
+	for i, objectiveValue := range before {
+		if geneticAlgorithm.population[i].objectiveValue < objectiveValue {
+			t.Errorf("solution %d has objective %f after local search, %f before", i,
+				geneticAlgorithm.population[i].objectiveValue, objectiveValue)
+		}
+	}
+	if geneticAlgorithm.population[0].objectiveValue <= before[0] {
+		t.Errorf("local search did not improve the best random itinerary")
+	}
+}
@@ -0,0 +1,284 @@
+package genetic_algorithm
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// itineraryLocks are the parts of an itinerary which re-optimization keeps. A locked day keeps all its visits with
+// their times. A locked visit stays on its day with its times and the other visits of the day are scheduled around
+// it. Itineraries carry their locks, so that copies and children keep them; nil locks nothing.
+type itineraryLocks struct {
+	days   map[int]Day     // locked days by their index
+	visits map[int][]Visit // locked visits of the other days by the index of their day
+	pois   map[*POI]int    // index of the day of every POI of a locked day or visit
+	start  *dayStart
+}
+
+// dayStart is a visit pinned at the beginning of a day with fixed times. It stands for the part of the day which
+// has already passed when the rest of a trip is re-planned, and is replaced by the visits completed in that time
+// in the result.
+type dayStart struct {
+	dayId     int
+	visit     Visit
+	completed []Visit
+}
+
+// SetLocks makes the next runs keep the days and visits marked as locked in the itinerary and search only over the
+// rest of the trip. Days are matched by their order and have to be the same days of the week as in the request,
+// POIs of locked visits have to be added before and locked visits of a day must leave time to walk between them.
+// nil removes the locks.
+func (ga *GeneticAlgorithm) SetLocks(itinerary *ApiItinerary) error {
+	if itinerary == nil {
+		ga.locks = nil
+		return nil
+	}
+	converted, err := convertFromApiItinerary(itinerary, ga.poiList)
+	if err != nil {
+		return err
+	}
+	byKey := poisByKey(ga.poiList)
+	locks := &itineraryLocks{days: make(map[int]Day), visits: make(map[int][]Visit), pois: make(map[*POI]int)}
+	for dayId, apiDay := range itinerary.Days {
+		lockedVisits := make([]*POI, 0)
+		for _, apiVisit := range apiDay.Visits {
+			if !apiDay.Locked && !apiVisit.Locked || apiVisit.Type == breakType {
+				continue
+			}
+			poi, ok := byKey[apiPoiKey(apiVisit.Poi)]
+			if !ok {
+				return fmt.Errorf("locked visit of unknown POI %q", apiVisit.Poi.Name)
+			}
+			if _, duplicate := locks.pois[poi]; duplicate {
+				return fmt.Errorf("POI %q is locked twice", poi.Name)
+			}
+			locks.pois[poi] = dayId
+			lockedVisits = append(lockedVisits, poi)
+		}
+		if !apiDay.Locked && len(lockedVisits) == 0 {
+			continue
+		}
+		if dayId >= len(ga.daysList) {
+			return fmt.Errorf("locked day %d is not a part of the trip", dayId)
+		}
+		if apiDay.DayName != ga.daysList[dayId] {
+			return fmt.Errorf("locked day %d is %s, not %s", dayId, apiDay.DayName, ga.daysList[dayId])
+		}
+		day := copyDay(converted.Days[dayId])
+		day.DayNumber = dayId
+		if apiDay.Locked {
+			locks.days[dayId] = day
+			continue
+		}
+		for _, visit := range day.Visits {
+			if containsPoi(lockedVisits, visit.Poi) {
+				locks.visits[dayId] = append(locks.visits[dayId], visit)
+			}
+		}
+		sort.SliceStable(locks.visits[dayId], func(i, j int) bool {
+			return locks.visits[dayId][i].StartVisit.Before(locks.visits[dayId][j].StartVisit)
+		})
+		lockedDay := Day{Visits: copyVisits(locks.visits[dayId]), DayName: day.DayName, fixed: locks.visits[dayId]}
+		repairDaySchedule(&lockedDay, ga.dayBeginHour, ga.dayEndHour)
+		if len(lockedDay.Visits) != len(locks.visits[dayId]) {
+			return fmt.Errorf("locked visits of day %d cannot be reached at their times", dayId)
+		}
+	}
+
+	ga.locks = locks
+	return nil
+}
+
+func (l *itineraryLocks) dayLocked(dayId int) bool {
+	if l == nil {
+		return false
+	}
+	_, locked := l.days[dayId]
+	return locked
+}
+
+// pinned is the number of visits at the beginning of the day which cannot change their position.
+func (l *itineraryLocks) pinned(dayId int) int {
+	if l == nil || l.start == nil || l.start.dayId != dayId {
+		return 0
+	}
+	return 1
+}
+
+func (l *itineraryLocks) visitLocked(poi *POI) bool {
+	if l == nil {
+		return false
+	}
+	_, locked := l.pois[poi]
+	return locked
+}
+
+// randomUnlockedVisit returns the index of a random visit of the day which is not locked, or -1 if there is none.
+func (l *itineraryLocks) randomUnlockedVisit(day Day, random *rand.Rand) int {
+	candidates := make([]int, 0, len(day.Visits))
+	for i, visit := range day.Visits {
+		if !l.visitLocked(visit.Poi) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	return candidates[random.Intn(len(candidates))]
+}
+
+// kept tells if the itinerary has all locked days unchanged and every locked visit on its day at its times.
+func (l *itineraryLocks) kept(itinerary *Itinerary) bool {
+	if l == nil {
+		return true
+	}
+	for dayId, locked := range l.days {
+		if dayId >= len(itinerary.Days) || !sameVisits(itinerary.Days[dayId].Visits, locked.Visits) {
+			return false
+		}
+	}
+	if l.start != nil {
+		if l.start.dayId >= len(itinerary.Days) || len(itinerary.Days[l.start.dayId].Visits) == 0 ||
+			!sameVisits(itinerary.Days[l.start.dayId].Visits[:1], []Visit{l.start.visit}) {
+			return false
+		}
+	}
+	for dayId, visits := range l.visits {
+		if dayId >= len(itinerary.Days) {
+			return false
+		}
+		for _, locked := range visits {
+			if !dayContainsVisit(itinerary.Days[dayId], locked) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sameVisits(a, b []Visit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Poi != b[i].Poi || !a[i].StartVisit.Equal(b[i].StartVisit) || !a[i].EndVisit.Equal(b[i].EndVisit) {
+			return false
+		}
+	}
+	return true
+}
+
+// dayContainsVisit tells if the day has a visit of the same POI at the same times.
+func dayContainsVisit(day Day, visit Visit) bool {
+	for _, other := range day.Visits {
+		if sameVisits([]Visit{other}, []Visit{visit}) {
+			return true
+		}
+	}
+	return false
+}
+
+func dayContainsPoi(day Day, poi *POI) bool {
+	for _, visit := range day.Visits {
+		if visit.Poi == poi {
+			return true
+		}
+	}
+	return false
+}
+
+// apply attaches the locks to the itinerary and changes it to keep them. Locked days are copied, locked POIs are
+// removed from the other days and every other day is rebuilt from its locked visits by inserting its remaining
+// visits in the order of their start, each one only if no visit has to be dropped.
+func (l *itineraryLocks) apply(itinerary *Itinerary) {
+	itinerary.locks = l
+	if l == nil {
+		return
+	}
+	for dayId := range itinerary.Days {
+		day := &itinerary.Days[dayId]
+		if locked, ok := l.days[dayId]; ok {
+			breaks := day.breaks
+			*day = copyDay(locked)
+			day.breaks = breaks
+			continue
+		}
+		others := make([]Visit, 0, len(day.Visits))
+		for _, visit := range day.Visits {
+			if !l.visitLocked(visit.Poi) {
+				others = append(others, visit)
+			}
+		}
+		*day = Day{Visits: copyVisits(l.visits[dayId]), DayNumber: day.DayNumber, DayName: day.DayName,
+			breaks: day.breaks, fixed: l.visits[dayId]}
+		if l.pinned(dayId) > 0 {
+			day.Visits = append([]Visit{l.start.visit}, day.Visits...)
+		}
+		repairDaySchedule(day, itinerary.DayBeginHour, itinerary.DayEndHour)
+		// visits are ordered by their start before scheduling, locked visits by their start in the locked itinerary
+		starts := make([]time.Time, 0, len(day.Visits)+len(others))
+		for _, visit := range day.Visits {
+			if l.pinned(dayId) > 0 && visit.Poi == l.start.visit.Poi {
+				starts = append(starts, l.start.visit.StartVisit)
+			}
+			for _, locked := range l.visits[dayId] {
+				if locked.Poi == visit.Poi {
+					starts = append(starts, locked.StartVisit)
+				}
+			}
+		}
+		for _, visit := range others {
+			position := sort.Search(len(starts), func(i int) bool {
+				return starts[i].After(visit.StartVisit)
+			})
+			visits := make([]Visit, 0, len(day.Visits)+1)
+			visits = append(visits, day.Visits[:position]...)
+			visits = append(visits, visit)
+			visits = append(visits, day.Visits[position:]...)
+			if tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour) {
+				starts = append(starts[:position], append([]time.Time{visit.StartVisit}, starts[position:]...)...)
+			}
+		}
+	}
+}
+
+// withCompleted replaces the pinned visit at the beginning of a day with the visits completed before it ended.
+func (l *itineraryLocks) withCompleted(itinerary Itinerary) Itinerary {
+	if l == nil || l.start == nil || l.pinned(l.start.dayId) == 0 || !l.kept(&itinerary) {
+		return itinerary
+	}
+	days := append([]Day(nil), itinerary.Days...)
+	day := &days[l.start.dayId]
+	visits := make([]Visit, 0, len(l.start.completed)+len(day.Visits)-1)
+	visits = append(visits, l.start.completed...)
+	day.Visits = append(visits, day.Visits[1:]...)
+	itinerary.Days = days
+	return itinerary
+}
+
+// enforce applies the locks to an itinerary which does not keep them.
+func (l *itineraryLocks) enforce(itinerary *Itinerary) {
+	if !l.kept(itinerary) {
+		l.apply(itinerary)
+	}
+}
+
+// LockedParts fails if an itinerary changed a locked day or moved a locked visit off its day or its times.
+type LockedParts struct {
+	next Constraint
+}
+
+func (c *LockedParts) execute(itinerary *Itinerary, failed *ConstraintsCount) {
+	if !itinerary.locks.kept(itinerary) {
+		failed.add("LockedParts")
+	}
+	if c.next != nil {
+		c.next.execute(itinerary, failed)
+	}
+}
+
+func (c *LockedParts) setNext(next Constraint) {
+	c.next = next
+}
@@ -0,0 +1,105 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// lockedTestProblem creates a genetic algorithm with the first day and the second visit of the second day of a
+// decoded plan locked, and returns the locked day and visit.
+func lockedTestProblem(t *testing.T) (*GeneticAlgorithm, Day, Visit) {
+	pois := randomPois(rand.New(rand.NewSource(7)), 14)
+	for _, poi := range pois {
+		for _, day := range testDays {
+			poi.OpenHour[day], poi.CloseHour[day] = clock(8*60), clock(20*60)
+		}
+	}
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	plan := decodeItinerary([][]*POI{pois[:3], pois[3:6]}, nil, clock(9*60), clock(19*60), testDays)
+	itinerary := convertToApiItinerary(&plan)
+	itinerary.Days[0].Locked = true
+	itinerary.Days[1].Visits[1].Locked = true
+	if err := geneticAlgorithm.SetLocks(&itinerary); err != nil {
+		t.Fatal(err)
+	}
+	return geneticAlgorithm, plan.Days[0], plan.Days[1].Visits[1]
+}
+
+// lockedTestItinerary is a random itinerary with the locks applied, like the ones of the initial population.
+func lockedTestItinerary(ga *GeneticAlgorithm, random *rand.Rand) Itinerary {
+	itinerary := GenerateRandomItinerary(ga.poiList, ga.dayBeginHour, ga.dayEndHour, ga.daysList, random)
+	ga.locks.apply(&itinerary)
+	return itinerary
+}
+
+func checkLocksKept(t *testing.T, operator string, itinerary *Itinerary, lockedDay Day, lockedVisit Visit) {
+	t.Helper()
+	if !sameVisits(itinerary.Days[0].Visits, lockedDay.Visits) {
+		t.Fatalf("%s changed the locked day to %v", operator, daySequence(itinerary.Days[0]))
+	}
+	if !dayContainsVisit(itinerary.Days[1], lockedVisit) {
+		t.Fatalf("%s moved the locked visit of %s, the second day is %+v", operator, lockedVisit.Poi.Name,
+			itinerary.Days[1].Visits)
+	}
+	failed := ConstraintsCount{}
+	(&OriginalPoi{}).execute(itinerary, &failed)
+	if failed.failedConstraints > 0 {
+		t.Fatalf("%s visits a POI twice", operator)
+	}
+}
+
+func TestOperatorsKeepLocks(t *testing.T) {
+	geneticAlgorithm, lockedDay, lockedVisit := lockedTestProblem(t)
+	random := rand.New(rand.NewSource(1))
+
+	for _, operator := range []CrossoverOperator{
+		DayBoundaryCrossover{}, OrderCrossover{}, PartiallyMappedCrossover{}, EdgeRecombinationCrossover{},
+	} {
+		parent := lockedTestItinerary(geneticAlgorithm, random)
+		for i := 0; i < 200; i++ {
+			other := lockedTestItinerary(geneticAlgorithm, random)
+			child1, child2 := operator.crossover(&parent, &other, geneticAlgorithm.poiList, random)
+			checkLocksKept(t, operator.name(), &child1, lockedDay, lockedVisit)
+			checkLocksKept(t, operator.name(), &child2, lockedDay, lockedVisit)
+			parent = child1
+		}
+	}
+
+	for _, operator := range AllMutationOperators() {
+		sol := solution{itinerary: lockedTestItinerary(geneticAlgorithm, random)}
+		for i := 0; i < 300; i++ {
+			operator.mutate(&sol, geneticAlgorithm.poiList, random)
+			checkLocksKept(t, operator.name(), &sol.itinerary, lockedDay, lockedVisit)
+		}
+	}
+
+	for _, move := range localSearchMoves {
+		for i := 0; i < 20; i++ {
+			search := &localSearchRun{
+				problem:       &geneticAlgorithm.problem,
+				itinerary:     lockedTestItinerary(geneticAlgorithm, random),
+				improvedMoves: make(map[string]bool),
+			}
+			search.objectiveValue, search.failedConstraints = geneticAlgorithm.evaluateItinerary(&search.itinerary)
+			for move.apply(search) {
+			}
+			checkLocksKept(t, move.name, &search.itinerary, lockedDay, lockedVisit)
+		}
+	}
+}
+
+func TestSetLocksRejectsUnreachableVisits(t *testing.T) {
+	geneticAlgorithm, _, _ := lockedTestProblem(t)
+	plan := decodeItinerary([][]*POI{nil, geneticAlgorithm.poiList[:2]}, nil, clock(9*60), clock(19*60), testDays)
+	// the second locked visit starts before the first one ends
+	plan.Days[1].Visits[1].StartVisit = plan.Days[1].Visits[0].StartVisit
+	itinerary := convertToApiItinerary(&plan)
+	itinerary.Days[1].Visits[0].Locked = true
+	itinerary.Days[1].Visits[1].Locked = true
+	if err := geneticAlgorithm.SetLocks(&itinerary); err == nil {
+		t.Errorf("overlapping locked visits are accepted")
+	}
+}
@@ -0,0 +1,109 @@
+package genetic_algorithm
+
+import "math/rand"
+
+// MutationSelection chooses the mutation operator for every offspring and may learn from the results. It is
+// only called from the goroutine running the algorithm.
+type MutationSelection interface {
+	// choose returns nil when the offspring should not be mutated
+	choose(random *rand.Rand) MutationOperator
+	update(operator string, improved bool)
+}
+
+type MutationRate struct {
+	Operator    MutationOperator
+	Probability float64
+}
+
+// FixedMutationRates applies each operator with its own fixed probability. At most one operator is applied to
+// an offspring, so the probabilities should not sum up to more than 1.
+type FixedMutationRates struct {
+	Rates []MutationRate
+}
+
+func (f FixedMutationRates) choose(random *rand.Rand) MutationOperator {
+	randomValue := random.Float64()
+	accumulated := 0.0
+	for _, rate := range f.Rates {
+		accumulated += rate.Probability
+		if randomValue < accumulated {
+			return rate.Operator
+		}
+	}
+	return nil
+}
+
+func (FixedMutationRates) update(string, bool) {}
+
+// AdaptivePursuit mutates an offspring with probability rate and chooses the operator with adaptive pursuit
+// (Thierens, 2005): the estimated quality of an operator follows the share of its offspring which were better
+// than their parents, and the probability of the best operator is pushed towards a maximum while all others are
+// pushed towards a minimum, so that no operator is ever abandoned completely.
+type AdaptivePursuit struct {
+	operators      []MutationOperator
+	rate           float64
+	minProbability float64
+	maxProbability float64
+	learningRate   float64
+	pursuitRate    float64
+	probabilities  []float64
+	qualities      []float64
+}
+
+func CreateAdaptivePursuit(operators []MutationOperator, rate float64) *AdaptivePursuit {
+	count := float64(len(operators))
+	minProbability := 1.0 / (3.0 * count)
+	ap := &AdaptivePursuit{
+		operators:      operators,
+		rate:           rate,
+		minProbability: minProbability,
+		maxProbability: 1.0 - (count-1.0)*minProbability,
+		learningRate:   0.3,
+		pursuitRate:    0.3,
+		probabilities:  make([]float64, len(operators)),
+		qualities:      make([]float64, len(operators)),
+	}
+	for i := range operators {
+		ap.probabilities[i] = 1.0 / count
+		ap.qualities[i] = 1.0
+	}
+	return ap
+}
+
+func (ap *AdaptivePursuit) choose(random *rand.Rand) MutationOperator {
+	if len(ap.operators) == 0 || random.Float64() >= ap.rate {
+		return nil
+	}
+	return ap.operators[pickByWeight(ap.probabilities, 1.0, random)]
+}
+
+func (ap *AdaptivePursuit) update(operator string, improved bool) {
+	index := -1
+	for i, op := range ap.operators {
+		if op.name() == operator {
+			index = i
+		}
+	}
+	if index < 0 {
+		return
+	}
+	reward := 0.0
+	if improved {
+		reward = 1.0
+	}
+	ap.qualities[index] += ap.learningRate * (reward - ap.qualities[index])
+
+	best := 0
+	for i, quality := range ap.qualities {
+		if quality > ap.qualities[best] {
+			best = i
+		}
+	}
+	for i := range ap.probabilities {
+		target := ap.minProbability
+		if i == best {
+			target = ap.maxProbability
+		}
+		ap.probabilities[i] += ap.pursuitRate * (target - ap.probabilities[i])
+	}
+}
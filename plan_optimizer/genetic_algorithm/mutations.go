@@ -5,28 +5,34 @@ import (
 	"time"
 )
 
-func substitutePOI(sol *solution, allPois []*POI, mutationProbability float64) {
-	rand.Seed(time.Now().UnixNano())
-
+func substitutePOI(sol *solution, allPois []*POI, mutationProbability float64, random *rand.Rand) bool {
 	// Filter out POIs already used in the solution
 	unusedPois := filterUnusedPois(sol, allPois)
+	unusedCount := len(unusedPois)
+
+	// locked days and visits are never substituted
+	locks := sol.itinerary.locks
 
 	// If the solution has only one day, randomly select one visit and try to exchange it
 	if len(sol.itinerary.Days) == 1 {
 		day := &sol.itinerary.Days[0]
-		if len(day.Visits) > 0 {
-			visitId := rand.Intn(len(day.Visits))
-			trySubstituteVisit(day, visitId, unusedPois, sol.itinerary.DayBeginHour, sol.itinerary.DayEndHour)
+		if visitId := locks.randomUnlockedVisit(*day, random); visitId >= 0 && !locks.dayLocked(0) {
+			unusedPois = trySubstituteVisit(day, visitId, unusedPois, sol.itinerary.DayBeginHour, sol.itinerary.DayEndHour,
+				random)
 		}
 	} else {
 		// If there are more than one day, apply the mutation with some probability for each day
 		for i, day := range sol.itinerary.Days {
-			if len(day.Visits) > 0 && rand.Float64() < mutationProbability {
-				visitId := rand.Intn(len(day.Visits))
-				unusedPois = trySubstituteVisit(&sol.itinerary.Days[i], visitId, unusedPois, sol.itinerary.DayBeginHour, sol.itinerary.DayEndHour)
+			if len(day.Visits) > 0 && !locks.dayLocked(i) && random.Float64() < mutationProbability {
+				if visitId := locks.randomUnlockedVisit(day, random); visitId >= 0 {
+					unusedPois = trySubstituteVisit(&sol.itinerary.Days[i], visitId, unusedPois, sol.itinerary.DayBeginHour,
+						sol.itinerary.DayEndHour, random)
+				}
 			}
 		}
 	}
+	// every successful substitution takes one POI from the unused ones
+	return len(unusedPois) < unusedCount
 }
 
 func filterUnusedPois(sol *solution, allPois []*POI) []*POI {
@@ -48,22 +54,18 @@ func filterUnusedPois(sol *solution, allPois []*POI) []*POI {
 	return unusedPois
 }
 
-func trySubstituteVisit(day *Day, visitId int, unusedPois []*POI, dayBeginHour, dayEndHour time.Time) []*POI {
-	visit := &day.Visits[visitId]
-
+// trySubstituteVisit replaces the POI of a visit with a random unused POI. The new POI keeps the duration of the
+// replaced visit and the day is scheduled again, so the substitution is only made if no visit has to be dropped.
+func trySubstituteVisit(day *Day, visitId int, unusedPois []*POI, dayBeginHour, dayEndHour time.Time,
+	random *rand.Rand) []*POI {
 	// Shuffle the unusedPois in random order
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(unusedPois), func(i, j int) {
+	random.Shuffle(len(unusedPois), func(i, j int) {
 		unusedPois[i], unusedPois[j] = unusedPois[j], unusedPois[i]
 	})
 	for i, newPoi := range unusedPois {
-		result, visitStart, visitEnd := doesNewPoiFit(newPoi, day.Visits, visitId, dayBeginHour, dayEndHour, day.DayName)
-		if result {
-			// Substitute the visit with the new POI
-			visit.Poi = newPoi
-			visit.StartVisit = visitStart
-			visit.EndVisit = visitEnd
-
+		visits := copyVisits(day.Visits)
+		visits[visitId].Poi = newPoi
+		if tryDaySchedule(day, visits, dayBeginHour, dayEndHour) {
 			// Delete the updated POI from unusedPois
 			unusedPois[i] = unusedPois[len(unusedPois)-1]
 			unusedPois = unusedPois[:len(unusedPois)-1]
@@ -74,3 +76,246 @@ func trySubstituteVisit(day *Day, visitId int, unusedPois []*POI, dayBeginHour,
 
 	return unusedPois
 }
+
+// MutationOperator changes a solution in place. It returns false if no change was possible. Operators never change
+// locked days and never remove locked visits, move them to another day or change their times.
+type MutationOperator interface {
+	name() string
+	mutate(sol *solution, allPois []*POI, random *rand.Rand) bool
+}
+
+// SubstituteMutation replaces visited POIs with unused ones, see substitutePOI.
+type SubstituteMutation struct {
+	DayProbability float64
+}
+
+func (SubstituteMutation) name() string {
+	return "substitute"
+}
+
+func (s SubstituteMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	return substitutePOI(sol, allPois, s.DayProbability, random)
+}
+
+// InsertMutation adds an unused POI at a random position of a random day, if it fits without dropping other visits.
+type InsertMutation struct{}
+
+func (InsertMutation) name() string {
+	return "insert"
+}
+
+func (InsertMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	itinerary := &sol.itinerary
+	dayId := randomDayWithVisits(itinerary, 0, random)
+	if dayId < 0 {
+		return false
+	}
+	unusedPois := filterUnusedPois(sol, allPois)
+	random.Shuffle(len(unusedPois), func(i, j int) {
+		unusedPois[i], unusedPois[j] = unusedPois[j], unusedPois[i]
+	})
+	day := &itinerary.Days[dayId]
+	pinned := itinerary.locks.pinned(dayId)
+	for attempt := 0; attempt < 10 && attempt < len(unusedPois); attempt++ {
+		position := pinned + random.Intn(len(day.Visits)-pinned+1)
+		visits := make([]Visit, 0, len(day.Visits)+1)
+		visits = append(visits, day.Visits[:position]...)
+		visits = append(visits, Visit{Poi: unusedPois[attempt], VisitDuration: random.Intn(121) + 60})
+		visits = append(visits, day.Visits[position:]...)
+		if tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveMutation removes a random visit which is not locked.
+type RemoveMutation struct{}
+
+func (RemoveMutation) name() string {
+	return "remove"
+}
+
+func (RemoveMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	dayId := randomDayWithVisits(&sol.itinerary, 1, random)
+	if dayId < 0 {
+		return false
+	}
+	day := &sol.itinerary.Days[dayId]
+	visitId := sol.itinerary.locks.randomUnlockedVisit(*day, random)
+	if visitId < 0 {
+		return false
+	}
+	day.Visits = append(day.Visits[:visitId], day.Visits[visitId+1:]...)
+	return true
+}
+
+// SwapMutation swaps two visits of the same day.
+type SwapMutation struct{}
+
+func (SwapMutation) name() string {
+	return "swap"
+}
+
+func (SwapMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	itinerary := &sol.itinerary
+	dayId := randomDayWithVisits(itinerary, 2, random)
+	if dayId < 0 {
+		return false
+	}
+	day := &itinerary.Days[dayId]
+	pinned := itinerary.locks.pinned(dayId)
+	if len(day.Visits)-pinned < 2 {
+		return false
+	}
+	i := random.Intn(len(day.Visits) - pinned)
+	j := pinned + (i+1+random.Intn(len(day.Visits)-pinned-1))%(len(day.Visits)-pinned)
+	i += pinned
+	visits := copyVisits(day.Visits)
+	visits[i], visits[j] = visits[j], visits[i]
+	return tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour)
+}
+
+// MoveMutation moves a visit which is not locked to a random position of another day.
+type MoveMutation struct{}
+
+func (MoveMutation) name() string {
+	return "move"
+}
+
+func (MoveMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	itinerary := &sol.itinerary
+	sourceId := randomDayWithVisits(itinerary, 1, random)
+	if sourceId < 0 || len(itinerary.Days) < 2 {
+		return false
+	}
+	targetId := (sourceId + 1 + random.Intn(len(itinerary.Days)-1)) % len(itinerary.Days)
+	if itinerary.locks.dayLocked(targetId) {
+		return false
+	}
+	source := &itinerary.Days[sourceId]
+	target := &itinerary.Days[targetId]
+
+	visitId := itinerary.locks.randomUnlockedVisit(*source, random)
+	if visitId < 0 {
+		return false
+	}
+	pinned := itinerary.locks.pinned(targetId)
+	position := pinned + random.Intn(len(target.Visits)-pinned+1)
+	visits := make([]Visit, 0, len(target.Visits)+1)
+	visits = append(visits, target.Visits[:position]...)
+	visits = append(visits, source.Visits[visitId])
+	visits = append(visits, target.Visits[position:]...)
+	if !tryDaySchedule(target, visits, itinerary.DayBeginHour, itinerary.DayEndHour) {
+		return false
+	}
+	source.Visits = append(source.Visits[:visitId], source.Visits[visitId+1:]...)
+	return true
+}
+
+// ReverseMutation reverses the order of a segment of visits within a day, which is the 2-opt move.
+type ReverseMutation struct{}
+
+func (ReverseMutation) name() string {
+	return "reverse"
+}
+
+func (ReverseMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	itinerary := &sol.itinerary
+	dayId := randomDayWithVisits(itinerary, 2, random)
+	if dayId < 0 {
+		return false
+	}
+	day := &itinerary.Days[dayId]
+	pinned := itinerary.locks.pinned(dayId)
+	if len(day.Visits)-pinned < 2 {
+		return false
+	}
+	start := pinned + random.Intn(len(day.Visits)-pinned-1)
+	end := start + 1 + random.Intn(len(day.Visits)-start-1)
+	visits := copyVisits(day.Visits)
+	for i, j := start, end; i < j; i, j = i+1, j-1 {
+		visits[i], visits[j] = visits[j], visits[i]
+	}
+	return tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour)
+}
+
+// ExtendMutation makes a random visit which is not locked 15 to 60 minutes longer or shorter and shifts the
+// following visits.
+type ExtendMutation struct{}
+
+func (ExtendMutation) name() string {
+	return "extend"
+}
+
+func (ExtendMutation) mutate(sol *solution, allPois []*POI, random *rand.Rand) bool {
+	itinerary := &sol.itinerary
+	dayId := randomDayWithVisits(itinerary, 1, random)
+	if dayId < 0 {
+		return false
+	}
+	day := &itinerary.Days[dayId]
+	visitId := itinerary.locks.randomUnlockedVisit(*day, random)
+	if visitId < 0 {
+		return false
+	}
+	change := 15 * (random.Intn(4) + 1)
+	if random.Intn(2) == 0 {
+		change = -change
+	}
+	visits := copyVisits(day.Visits)
+	visits[visitId].VisitDuration = calculateDuration(visits[visitId].StartVisit, visits[visitId].EndVisit) + change
+	if visits[visitId].VisitDuration < minimumVisitDuration {
+		visits[visitId].VisitDuration = minimumVisitDuration
+	}
+	before := day.Visits[visitId]
+	if !tryDaySchedule(day, visits, itinerary.DayBeginHour, itinerary.DayEndHour) {
+		return false
+	}
+	return !day.Visits[visitId].StartVisit.Equal(before.StartVisit) || !day.Visits[visitId].EndVisit.Equal(before.EndVisit)
+}
+
+func copyVisits(visits []Visit) []Visit {
+	return append(make([]Visit, 0, len(visits)), visits...)
+}
+
+// randomDayWithVisits returns the index of a random day which is not locked and has at least minVisits visits, or
+// -1 if there is none.
+func randomDayWithVisits(itinerary *Itinerary, minVisits int, random *rand.Rand) int {
+	candidates := make([]int, 0, len(itinerary.Days))
+	for i, day := range itinerary.Days {
+		if len(day.Visits) >= minVisits && !itinerary.locks.dayLocked(i) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+	return candidates[random.Intn(len(candidates))]
+}
+
+// tryDaySchedule recomputes times for the given order of visits and replaces the visits of the day only if none
+// of them had to be dropped.
+func tryDaySchedule(day *Day, visits []Visit, dayBeginHour, dayEndHour time.Time) bool {
+	candidate := day.withoutVisits()
+	candidate.Visits = visits
+	repairDaySchedule(&candidate, dayBeginHour, dayEndHour)
+	if len(candidate.Visits) != len(visits) {
+		return false
+	}
+	day.Visits = candidate.Visits
+	return true
+}
+
+// AllMutationOperators returns every available mutation operator.
+func AllMutationOperators() []MutationOperator {
+	return []MutationOperator{
+		SubstituteMutation{DayProbability: DAY_MUTATION_PROBABILITY},
+		InsertMutation{},
+		RemoveMutation{},
+		SwapMutation{},
+		MoveMutation{},
+		ReverseMutation{},
+		ExtendMutation{},
+	}
+}
@@ -0,0 +1,164 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAdaptivePursuitFollowsReward(t *testing.T) {
+	operators := AllMutationOperators()
+	pursuit := CreateAdaptivePursuit(operators, 1.0)
+	rewarded := 3
+	initial := pursuit.probabilities[rewarded]
+
+	pursuit.update("unknown", true)
+	for i := 0; i < 30; i++ {
+		for j, operator := range operators {
+			pursuit.update(operator.name(), j == rewarded)
+		}
+	}
+
+	sum := 0.0
+	for i, probability := range pursuit.probabilities {
+		sum += probability
+		if i == rewarded {
+			continue
+		}
+		if probability >= initial || probability < pursuit.minProbability-1e-9 {
+			t.Errorf("operator %s without rewards has probability %f", operators[i].name(), probability)
+		}
+	}
+	if probability := pursuit.probabilities[rewarded]; probability <= initial ||
+		math.Abs(probability-pursuit.maxProbability) > 1e-3 {
+		t.Errorf("rewarded operator has probability %f, expected %f", probability, pursuit.maxProbability)
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("probabilities sum up to %f", sum)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	chosen := 0
+	for i := 0; i < 10000; i++ {
+		if pursuit.choose(random).name() == operators[rewarded].name() {
+			chosen++
+		}
+	}
+	if share := float64(chosen) / 10000; math.Abs(share-pursuit.maxProbability) > 0.02 {
+		t.Errorf("rewarded operator chosen for %f of offspring, expected %f", share, pursuit.maxProbability)
+	}
+}
+
+// mutationTestSolution visits three of the POIs for 90 minutes on each day, the other POIs are unused.
+func mutationTestSolution(pois []*POI) solution {
+	durations := make(map[*POI]int)
+	for _, poi := range pois {
+		durations[poi] = 90
+	}
+	return solution{itinerary: decodeItinerary([][]*POI{pois[:3], pois[3:6]}, durations, clock(9*60), clock(19*60),
+		testDays)}
+}
+
+// visitedNames returns the sorted names of the POIs visited on every day.
+func visitedNames(itinerary *Itinerary) [][]string {
+	names := make([][]string, len(itinerary.Days))
+	for dayId, day := range itinerary.Days {
+		names[dayId] = make([]string, 0, len(day.Visits))
+		for _, visit := range day.Visits {
+			names[dayId] = append(names[dayId], visit.Poi.Name)
+		}
+		sort.Strings(names[dayId])
+	}
+	return names
+}
+
+func visitCount(itinerary *Itinerary) int {
+	count := 0
+	for _, day := range itinerary.Days {
+		count += len(day.Visits)
+	}
+	return count
+}
+
+func TestMutationOperators(t *testing.T) {
+	pois := breakTestPois(10)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	sameDays := func(before, after *Itinerary) bool {
+		return reflect.DeepEqual(visitedNames(before), visitedNames(after))
+	}
+	sameOrder := func(before, after *Itinerary) bool {
+		for dayId := range before.Days {
+			if !reflect.DeepEqual(daySequence(before.Days[dayId]), daySequence(after.Days[dayId])) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, test := range []struct {
+		operator MutationOperator
+		changed  func(before, after *Itinerary) bool
+	}{
+		{SubstituteMutation{DayProbability: 1}, func(before, after *Itinerary) bool {
+			return visitCount(after) == visitCount(before) && !sameDays(before, after)
+		}},
+		{InsertMutation{}, func(before, after *Itinerary) bool {
+			return visitCount(after) == visitCount(before)+1
+		}},
+		{RemoveMutation{}, func(before, after *Itinerary) bool {
+			return visitCount(after) == visitCount(before)-1
+		}},
+		{SwapMutation{}, func(before, after *Itinerary) bool {
+			return sameDays(before, after) && !sameOrder(before, after)
+		}},
+		{MoveMutation{}, func(before, after *Itinerary) bool {
+			return visitCount(after) == visitCount(before) && len(after.Days[0].Visits) != len(before.Days[0].Visits)
+		}},
+		{ReverseMutation{}, func(before, after *Itinerary) bool {
+			return sameDays(before, after) && !sameOrder(before, after)
+		}},
+		{ExtendMutation{}, func(before, after *Itinerary) bool {
+			if !sameOrder(before, after) {
+				return false
+			}
+			changed := 0
+			for dayId, day := range after.Days {
+				for visitId, visit := range day.Visits {
+					if visit.VisitDuration != before.Days[dayId].Visits[visitId].VisitDuration {
+						changed++
+					}
+				}
+			}
+			return changed == 1
+		}},
+	} {
+		random := rand.New(rand.NewSource(19))
+		mutated := 0
+		for i := 0; i < 50; i++ {
+			before := mutationTestSolution(pois)
+			sol := solution{itinerary: copyItinerary(&before.itinerary)}
+			if !test.operator.mutate(&sol, pois, random) {
+				if !sameOrder(&before.itinerary, &sol.itinerary) {
+					t.Fatalf("%s changed the itinerary without success", test.operator.name())
+				}
+				continue
+			}
+			mutated++
+			if !test.changed(&before.itinerary, &sol.itinerary) {
+				t.Fatalf("%s changed %v to %v", test.operator.name(), visitedNames(&before.itinerary),
+					visitedNames(&sol.itinerary))
+			}
+			if _, failed := geneticAlgorithm.evaluateItinerary(&sol.itinerary); failed > 0 {
+				t.Fatalf("%s created an itinerary failing %d constraints", test.operator.name(), failed)
+			}
+		}
+		if mutated == 0 {
+			t.Errorf("%s never succeeded", test.operator.name())
+		}
+	}
+}
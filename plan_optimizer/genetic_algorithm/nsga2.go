@@ -0,0 +1,223 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math"
+	"sort"
+)
+
+// ObjectiveVector holds the objectives optimized separately by SolvePareto. Satisfaction and Pois are maximized,
+// TravelMinutes is minimized.
+type ObjectiveVector struct {
+	Satisfaction  float64 `json:"satisfaction"` // sum of satisfaction weighted by the part of a day spent in the POI
+	TravelMinutes int     `json:"travelMinutes"`
+	Pois          int     `json:"pois"`
+}
+
+// ParetoSolution is an itinerary from the Pareto front with its objectives.
+type ParetoSolution struct {
+	Itinerary  ApiItinerary    `json:"itinerary"`
+	Objectives ObjectiveVector `json:"objectives"`
+}
+
+func calculateObjectives(itinerary *Itinerary) ObjectiveVector {
+	objectives := ObjectiveVector{}
+	for _, day := range itinerary.Days {
+		objectives.TravelMinutes += dayTravelTime(day)
+		for _, visit := range day.Visits {
+			objectives.Pois++
+			objectives.Satisfaction += float64(visit.VisitDuration) / (24.0 * 60.0) * visit.Poi.Satisfaction
+		}
+	}
+	return objectives
+}
+
+// dominates tells if a is not worse than b in any objective and better in at least one.
+func (a ObjectiveVector) dominates(b ObjectiveVector) bool {
+	if a.Satisfaction < b.Satisfaction || a.TravelMinutes > b.TravelMinutes || a.Pois < b.Pois {
+		return false
+	}
+	return a.Satisfaction > b.Satisfaction || a.TravelMinutes < b.TravelMinutes || a.Pois > b.Pois
+}
+
+// constrainedDominates prefers solutions which fail fewer constraints and compares objectives of the other ones.
+func constrainedDominates(a, b *solution) bool {
+	if a.failedConstraints != b.failedConstraints {
+		return a.failedConstraints < b.failedConstraints
+	}
+	return a.objectives.dominates(b.objectives)
+}
+
+// SolvePareto runs NSGA-II with the crossover and mutation operators of the algorithm and the parameters set by
+// SetRunParameters. Instead of the weighted objective function it optimizes satisfaction, travel time and number
+// of POIs as separate objectives and returns the feasible non-dominated itineraries, from the one with the fewest POIs.
+// The selection and replacement strategies are replaced by crowded tournament selection and non-dominated sorting.
+// There is no single best objective, so only the time budget and the minimum diversity of the termination
+// conditions stop the run early. Local search, restarts, alternatives, the island model and the stability penalty
+// are not used.
+func (ga *GeneticAlgorithm) SolvePareto(ctx context.Context) ([]ParetoSolution, RunReport) {
+	ga.startRun()
+	if ga.mutation == nil {
+		ga.mutation = CreateAdaptivePursuit(AllMutationOperators(), MUTATION_PROBABILITY)
+	}
+	check, ctx, cancel := ga.startTermination(ctx)
+	defer cancel()
+	if ga.termination != nil {
+		check.termination = &Termination{MinimumDiversity: ga.termination.MinimumDiversity}
+	}
+	ga.createInitialPopulation(ga.populationSize, ga.seededCount(ga.populationSize))
+	ga.assessObjectives(ga.population)
+	ga.population = selectByFronts(ga.population, ga.populationSize)
+
+	// objectiveValue holds the crowded comparison fitness, so binary tournaments pick the better rank and then
+	// the less crowded solution
+	selection := TournamentSelection{Size: 2}
+	ga.stopReason = StopIterations
+	for i := 0; i < ga.iterations; i++ {
+		if reason := check.stopReason(ctx, i, 0, ga.population); reason != "" {
+			ga.stopReason = reason
+			break
+		}
+		offspring := ga.createOffspring(selection, ga.populationSize)
+		ga.assessObjectives(offspring)
+		for j := range offspring {
+			offspring[j].age = -1
+		}
+		ga.population = selectByFronts(mergeSolutions(ga.population, offspring), ga.populationSize)
+
+		// offspring is credited when it reaches the first front
+		for _, sol := range ga.population {
+			if sol.age == -1 {
+				improved := sol.objectiveValue >= 0
+				ga.recordOperator("crossover", sol.origin.crossover, improved)
+				if sol.origin.mutation != "" {
+					ga.recordOperator("mutation", sol.origin.mutation, improved)
+					ga.mutation.update(sol.origin.mutation, improved)
+				}
+			}
+		}
+		for j := range ga.population {
+			ga.population[j].age++
+		}
+	}
+
+	front := make([]ParetoSolution, 0)
+	// one itinerary is returned for every objective vector
+	seen := make(map[ObjectiveVector]bool)
+	for _, sol := range ga.population {
+		if sol.objectiveValue < 0 || sol.failedConstraints > 0 || seen[sol.objectives] {
+			continue
+		}
+		seen[sol.objectives] = true
+		front = append(front, ParetoSolution{Itinerary: ga.result(sol.itinerary), Objectives: sol.objectives})
+	}
+	sort.SliceStable(front, func(i, j int) bool {
+		if front[i].Objectives.Pois != front[j].Objectives.Pois {
+			return front[i].Objectives.Pois < front[j].Objectives.Pois
+		}
+		return front[i].Objectives.Satisfaction < front[j].Objectives.Satisfaction
+	})
+	return front, ga.Report()
+}
+
+func (ga *GeneticAlgorithm) assessObjectives(solutions []solution) {
+	for i := range solutions {
+		failedConstraints := ConstraintsCount{}
+		ga.constraints.execute(&solutions[i].itinerary, &failedConstraints)
+		solutions[i].failedConstraints = failedConstraints.failedConstraints
+		ga.countConstraintFailures(&failedConstraints)
+		solutions[i].objectives = calculateObjectives(&solutions[i].itinerary)
+	}
+}
+
+// nonDominatedFronts sorts solutions into fronts, the first front holds solutions not dominated by any other.
+func nonDominatedFronts(solutions []solution) [][]int {
+	dominatedBy := make([]int, len(solutions))
+	dominating := make([][]int, len(solutions))
+	fronts := [][]int{make([]int, 0)}
+	for i := range solutions {
+		for j := range solutions {
+			if i == j {
+				continue
+			}
+			if constrainedDominates(&solutions[i], &solutions[j]) {
+				dominating[i] = append(dominating[i], j)
+			} else if constrainedDominates(&solutions[j], &solutions[i]) {
+				dominatedBy[i]++
+			}
+		}
+		if dominatedBy[i] == 0 {
+			fronts[0] = append(fronts[0], i)
+		}
+	}
+	for current := 0; len(fronts[current]) > 0; current++ {
+		next := make([]int, 0)
+		for _, i := range fronts[current] {
+			for _, j := range dominating[i] {
+				dominatedBy[j]--
+				if dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		fronts = append(fronts, next)
+	}
+	return fronts[:len(fronts)-1]
+}
+
+// crowdingDistances measures how far the solutions of a front are from their neighbours in every objective.
+// Solutions at the ends of the front get an infinite distance.
+func crowdingDistances(solutions []solution, front []int) map[int]float64 {
+	distances := make(map[int]float64, len(front))
+	objectives := []func(sol *solution) float64{
+		func(sol *solution) float64 { return sol.objectives.Satisfaction },
+		func(sol *solution) float64 { return float64(sol.objectives.TravelMinutes) },
+		func(sol *solution) float64 { return float64(sol.objectives.Pois) },
+	}
+	order := append([]int(nil), front...)
+	for _, objective := range objectives {
+		sort.SliceStable(order, func(i, j int) bool {
+			return objective(&solutions[order[i]]) < objective(&solutions[order[j]])
+		})
+		minValue, maxValue := objective(&solutions[order[0]]), objective(&solutions[order[len(order)-1]])
+		distances[order[0]] = math.Inf(1)
+		distances[order[len(order)-1]] = math.Inf(1)
+		if maxValue == minValue {
+			continue
+		}
+		for k := 1; k < len(order)-1; k++ {
+			distances[order[k]] += (objective(&solutions[order[k+1]]) - objective(&solutions[order[k-1]])) /
+				(maxValue - minValue)
+		}
+	}
+	return distances
+}
+
+// selectByFronts keeps size solutions from the best fronts, the last front is cut by crowding distance. The
+// objective value of every kept solution is set to its crowded comparison fitness: minus the index of its front
+// plus a number below one growing with the crowding distance.
+func selectByFronts(solutions []solution, size int) []solution {
+	selected := make([]solution, 0, size)
+	for rank, front := range nonDominatedFronts(solutions) {
+		if len(selected) >= size {
+			break
+		}
+		distances := crowdingDistances(solutions, front)
+		sort.SliceStable(front, func(i, j int) bool {
+			return distances[front[i]] > distances[front[j]]
+		})
+		for _, i := range front {
+			if len(selected) >= size {
+				break
+			}
+			sol := solutions[i]
+			crowding := 1.0
+			if !math.IsInf(distances[i], 1) {
+				crowding = distances[i] / (1.0 + distances[i])
+			}
+			sol.objectiveValue = -float64(rank) + 0.5*crowding
+			selected = append(selected, sol)
+		}
+	}
+	return selected
+}
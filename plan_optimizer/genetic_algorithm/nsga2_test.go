@@ -0,0 +1,124 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func populationWithObjectiveVectors(vectors ...ObjectiveVector) []solution {
+	population := make([]solution, len(vectors))
+	for i, objectives := range vectors {
+		population[i] = solution{objectives: objectives}
+	}
+	return population
+}
+
+func TestNonDominatedFronts(t *testing.T) {
+	population := populationWithObjectiveVectors(
+		ObjectiveVector{Satisfaction: 10, TravelMinutes: 100, Pois: 3},
+		ObjectiveVector{Satisfaction: 8, TravelMinutes: 50, Pois: 3},
+		ObjectiveVector{Satisfaction: 5, TravelMinutes: 200, Pois: 2},
+		ObjectiveVector{Satisfaction: 9, TravelMinutes: 120, Pois: 3},
+		ObjectiveVector{Satisfaction: 20, TravelMinutes: 10, Pois: 5},
+		ObjectiveVector{Satisfaction: 4, TravelMinutes: 300, Pois: 1},
+	)
+	// the best objectives do not help an itinerary which fails a constraint
+	population[4].failedConstraints = 1
+
+	fronts := nonDominatedFronts(population)
+	expected := [][]int{{0, 1}, {3}, {2}, {5}, {4}}
+	if !reflect.DeepEqual(fronts, expected) {
+		t.Errorf("fronts %v, expected %v", fronts, expected)
+	}
+}
+
+// crowdedFront has four non-dominated solutions, the first and the last one at the ends of every objective.
+var crowdedFront = []ObjectiveVector{
+	{Satisfaction: 1, TravelMinutes: 10, Pois: 1},
+	{Satisfaction: 2, TravelMinutes: 20, Pois: 2},
+	{Satisfaction: 3, TravelMinutes: 25, Pois: 2},
+	{Satisfaction: 4, TravelMinutes: 40, Pois: 3},
+}
+
+func TestCrowdingDistances(t *testing.T) {
+	population := populationWithObjectiveVectors(crowdedFront...)
+	distances := crowdingDistances(population, []int{0, 1, 2, 3})
+	// sums of the distances between the neighbours in satisfaction, travel time and POIs, divided by their ranges
+	expected := map[int]float64{
+		0: math.Inf(1),
+		1: 2.0/3.0 + 15.0/30.0 + 1.0/2.0,
+		2: 2.0/3.0 + 20.0/30.0 + 1.0/2.0,
+		3: math.Inf(1),
+	}
+	for i, distance := range expected {
+		if math.IsInf(distance, 1) != math.IsInf(distances[i], 1) || math.Abs(distances[i]-distance) > 1e-9 {
+			t.Errorf("solution %d has crowding distance %f, expected %f", i, distances[i], distance)
+		}
+	}
+}
+
+func TestSelectByFronts(t *testing.T) {
+	dominated := ObjectiveVector{Satisfaction: 1, TravelMinutes: 50, Pois: 1}
+	population := populationWithObjectiveVectors(append(crowdedFront, dominated)...)
+	for i := range population {
+		population[i].age = i
+	}
+
+	// the last kept front is cut by crowding distance
+	selected := selectByFronts(population, 3)
+	ages := make([]int, len(selected))
+	for i, sol := range selected {
+		ages[i] = sol.age
+	}
+	if !reflect.DeepEqual(ages, []int{0, 3, 2}) {
+		t.Errorf("selected solutions %v, expected the ends of the front and the less crowded one", ages)
+	}
+	distance := 2.0/3.0 + 20.0/30.0 + 1.0/2.0
+	fitness := []float64{0.5, 0.5, 0.5 * distance / (1.0 + distance)}
+	for i, sol := range selected {
+		if math.Abs(sol.objectiveValue-fitness[i]) > 1e-9 {
+			t.Errorf("solution %d has fitness %f, expected %f", sol.age, sol.objectiveValue, fitness[i])
+		}
+	}
+
+	// solutions of the second front have a lower fitness than any solution of the first front
+	selected = selectByFronts(population, len(population))
+	if last := selected[len(selected)-1]; last.age != 4 || last.objectiveValue != -0.5 {
+		t.Errorf("dominated solution %d is selected last with fitness %f", last.age, last.objectiveValue)
+	}
+}
+
+func TestSolveParetoStopsEarly(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(8)), 30)
+	createGa := func(termination *Termination) *GeneticAlgorithm {
+		geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+		for _, poi := range pois {
+			geneticAlgorithm.AddPoi(poi)
+		}
+		geneticAlgorithm.SetRunParameters(40, 1000000, 8)
+		geneticAlgorithm.SetSeed(1)
+		geneticAlgorithm.SetTermination(termination)
+		return geneticAlgorithm
+	}
+
+	front, report := createGa(&Termination{TimeBudget: 50 * time.Millisecond}).SolvePareto(context.Background())
+	if report.StopReason != StopTimeBudget || len(front) == 0 {
+		t.Errorf("run with a time budget stopped with %q and a front of %d itineraries", report.StopReason, len(front))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, report = createGa(nil).SolvePareto(ctx); report.StopReason != StopCancelled {
+		t.Errorf("cancelled run stopped with %q", report.StopReason)
+	}
+
+	geneticAlgorithm := createGa(nil)
+	geneticAlgorithm.SetRunParameters(40, 5, 8)
+	if _, report = geneticAlgorithm.SolvePareto(context.Background()); report.StopReason != StopIterations {
+		t.Errorf("run of all iterations stopped with %q", report.StopReason)
+	}
+}
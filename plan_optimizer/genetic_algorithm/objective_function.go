@@ -1,7 +1,8 @@
 package genetic_algorithm
 
+// objectiveFunction sets the objective value of the solution. stabilityPenalty is subtracted from it, see Stability.
 func objectiveFunction(s *solution, failedConstraints int, poiMultiplier float64, penaltyMultiplier float64,
-	satisfactionMultiplier float64) {
+	satisfactionMultiplier float64, stabilityPenalty float64) {
 	var satisfaction float64
 	var numberOfPoi = 0.0
 
@@ -11,5 +12,33 @@ func objectiveFunction(s *solution, failedConstraints int, poiMultiplier float64
 			satisfaction += float64(visit.VisitDuration) / (24.0 * 60.0) * visit.Poi.Satisfaction
 		}
 	}
-	s.objectiveValue = satisfactionMultiplier*satisfaction + numberOfPoi*poiMultiplier - penaltyMultiplier*float64(failedConstraints)
+	s.objectiveValue = satisfactionMultiplier*satisfaction + numberOfPoi*poiMultiplier - penaltyMultiplier*float64(failedConstraints) -
+		stabilityPenalty
+}
+
+// ObjectiveBreakdown shows the terms of the objective function of an itinerary.
+type ObjectiveBreakdown struct {
+	Satisfaction      float64 `json:"satisfaction"` // satisfaction weighted by the part of a day spent in POIs
+	Pois              float64 `json:"pois"`         // value of the number of visited POIs
+	Penalty           float64 `json:"penalty"`      // penalty for failed constraints
+	FailedConstraints int     `json:"failedConstraints"`
+	Stability         float64 `json:"stability"` // penalty for differences from the reference itinerary
+	Total             float64 `json:"total"`
+}
+
+func (p *problem) objectiveBreakdown(itinerary *Itinerary) ObjectiveBreakdown {
+	failedConstraints := ConstraintsCount{}
+	p.constraints.execute(itinerary, &failedConstraints)
+	breakdown := ObjectiveBreakdown{FailedConstraints: failedConstraints.failedConstraints}
+	for _, day := range itinerary.Days {
+		for _, visit := range day.Visits {
+			breakdown.Pois += p.poiMultiplier
+			breakdown.Satisfaction += p.satisfactionMultiplier * float64(visit.VisitDuration) / (24.0 * 60.0) *
+				visit.Poi.Satisfaction
+		}
+	}
+	breakdown.Penalty = p.penaltyMultiplier * float64(breakdown.FailedConstraints)
+	breakdown.Stability = p.stabilityPenalty(itinerary)
+	breakdown.Total = breakdown.Satisfaction + breakdown.Pois - breakdown.Penalty - breakdown.Stability
+	return breakdown
 }
@@ -0,0 +1,65 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"strings"
+)
+
+// encodePolyline encodes coordinates with the Encoded Polyline Algorithm Format (precision 5), which is
+// understood by Leaflet plugins, Google Maps and most routing tools.
+func encodePolyline(coordinates []Coordinate) string {
+	var builder strings.Builder
+	var prevLat, prevLon int64
+	for _, c := range coordinates {
+		lat := int64(math.Round(c.Lat * 1e5))
+		lon := int64(math.Round(c.Lon * 1e5))
+		encodePolylineValue(&builder, lat-prevLat)
+		encodePolylineValue(&builder, lon-prevLon)
+		prevLat, prevLon = lat, lon
+	}
+	return builder.String()
+}
+
+func encodePolylineValue(builder *strings.Builder, value int64) {
+	shifted := value << 1
+	if value < 0 {
+		shifted = ^shifted
+	}
+	for shifted >= 0x20 {
+		builder.WriteByte(byte((0x20 | (shifted & 0x1f)) + 63))
+		shifted >>= 5
+	}
+	builder.WriteByte(byte(shifted + 63))
+}
+
+// decodePolyline is the inverse of encodePolyline.
+func decodePolyline(encoded string) []Coordinate {
+	coordinates := make([]Coordinate, 0)
+	var lat, lon int64
+	index := 0
+	for index < len(encoded) {
+		var values [2]int64
+		for k := range values {
+			var result int64
+			shift := uint(0)
+			for index < len(encoded) {
+				b := int64(encoded[index]) - 63
+				index++
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				values[k] = ^(result >> 1)
+			} else {
+				values[k] = result >> 1
+			}
+		}
+		lat += values[0]
+		lon += values[1]
+		coordinates = append(coordinates, Coordinate{Lat: float64(lat) / 1e5, Lon: float64(lon) / 1e5})
+	}
+	return coordinates
+}
@@ -0,0 +1,37 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestEncodePolylineReference(t *testing.T) {
+	// the example of the Encoded Polyline Algorithm Format documentation
+	coordinates := []Coordinate{{Lat: 38.5, Lon: -120.2}, {Lat: 40.7, Lon: -120.95}, {Lat: 43.252, Lon: -126.453}}
+	if encoded := encodePolyline(coordinates); encoded != "_p~iF~ps|U_ulLnnqC_mqNvxq`@" {
+		t.Errorf("reference coordinates encoded as %q", encoded)
+	}
+	if encoded := encodePolyline(nil); encoded != "" {
+		t.Errorf("empty polyline encoded as %q", encoded)
+	}
+}
+
+func TestPolylineRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(23))
+	for i := 0; i < 100; i++ {
+		coordinates := make([]Coordinate, random.Intn(20))
+		for j := range coordinates {
+			coordinates[j] = Coordinate{Lat: 180*random.Float64() - 90, Lon: 360*random.Float64() - 180}
+		}
+		decoded := decodePolyline(encodePolyline(coordinates))
+		if len(decoded) != len(coordinates) {
+			t.Fatalf("%d coordinates decoded as %d", len(coordinates), len(decoded))
+		}
+		for j, c := range coordinates {
+			if math.Abs(decoded[j].Lat-c.Lat) > 0.5e-5+1e-9 || math.Abs(decoded[j].Lon-c.Lon) > 0.5e-5+1e-9 {
+				t.Fatalf("%v decoded as %v", c, decoded[j])
+			}
+		}
+	}
+}
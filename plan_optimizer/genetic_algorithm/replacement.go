@@ -0,0 +1,187 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+)
+
+// ReplacementStrategy decides how many offspring are created in a generation and which solutions form the next
+// population. The returned population is sorted from the best solution and never exceeds populationSize.
+type ReplacementStrategy interface {
+	offspringCount(populationSize int, random *rand.Rand) int
+	replace(population []solution, offspring []solution, populationSize int, random *rand.Rand) []solution
+}
+
+// defaultOffspringCount creates between 10% and 20% of the population size pairs of children.
+func defaultOffspringCount(populationSize int, random *rand.Rand) int {
+	minPairs := populationSize / 10
+	maxPairs := populationSize / 5
+	if maxPairs <= minPairs {
+		return 2 * (minPairs + 1)
+	}
+	return 2 * (random.Intn(maxPairs-minPairs) + minPairs + 1)
+}
+
+func mergeSolutions(population []solution, offspring []solution) []solution {
+	merged := make([]solution, 0, len(population)+len(offspring))
+	merged = append(merged, population...)
+	return append(merged, offspring...)
+}
+
+func truncateSolutions(solutions []solution, size int) []solution {
+	sortSolutions(solutions)
+	if len(solutions) > size {
+		solutions = solutions[:size]
+	}
+	return solutions
+}
+
+// PlusReplacement is the (μ+λ) strategy: parents and offspring compete together and the best μ survive.
+type PlusReplacement struct{}
+
+func (PlusReplacement) offspringCount(populationSize int, random *rand.Rand) int {
+	return defaultOffspringCount(populationSize, random)
+}
+
+func (PlusReplacement) replace(population []solution, offspring []solution, populationSize int,
+	random *rand.Rand) []solution {
+	return truncateSolutions(mergeSolutions(population, offspring), populationSize)
+}
+
+// CommaReplacement is the (μ,λ) strategy: parents are discarded and the best μ offspring survive. OffspringRatio
+// is λ/μ and has to be at least 1, by default 1.5 offspring are created per parent.
+type CommaReplacement struct {
+	OffspringRatio float64
+}
+
+func (c CommaReplacement) offspringCount(populationSize int, random *rand.Rand) int {
+	ratio := c.OffspringRatio
+	if ratio < 1.0 {
+		ratio = 1.5
+	}
+	return int(math.Ceil(ratio * float64(populationSize)))
+}
+
+func (CommaReplacement) replace(population []solution, offspring []solution, populationSize int,
+	random *rand.Rand) []solution {
+	if len(offspring) == 0 {
+		return truncateSolutions(population, populationSize)
+	}
+	return truncateSolutions(mergeSolutions(nil, offspring), populationSize)
+}
+
+// SteadyStateReplacement replaces only the worst solutions with a few offspring per generation. The best Elitism
+// solutions are never replaced. Offspring is the number of children per generation, 10% of the population by
+// default.
+type SteadyStateReplacement struct {
+	Elitism   int
+	Offspring int
+}
+
+func (s SteadyStateReplacement) offspringCount(populationSize int, random *rand.Rand) int {
+	if s.Offspring > 0 {
+		return s.Offspring
+	}
+	count := populationSize / 10
+	if count < 2 {
+		count = 2
+	}
+	return count
+}
+
+func (s SteadyStateReplacement) replace(population []solution, offspring []solution, populationSize int,
+	random *rand.Rand) []solution {
+	population = truncateSolutions(mergeSolutions(population, nil), populationSize)
+	elitism := s.Elitism
+	if elitism > len(population) {
+		elitism = len(population)
+	}
+	// only the part of the population below the elite can be replaced
+	replaceable := len(population) - elitism
+	if len(offspring) > populationSize-elitism {
+		sortSolutions(offspring)
+		offspring = offspring[:populationSize-elitism]
+	}
+	free := populationSize - len(population)
+	toRemove := len(offspring) - free
+	if toRemove > replaceable {
+		toRemove = replaceable
+	}
+	if toRemove > 0 {
+		population = population[:len(population)-toRemove]
+	}
+	return truncateSolutions(mergeSolutions(population, offspring), populationSize)
+}
+
+// AgeBasedReplacement removes solutions which survived more than TTL generations, except the best Elitism
+// solutions, and then keeps the best ones up to the population size.
+type AgeBasedReplacement struct {
+	TTL     int
+	Elitism int
+}
+
+func (AgeBasedReplacement) offspringCount(populationSize int, random *rand.Rand) int {
+	return defaultOffspringCount(populationSize, random)
+}
+
+func (a AgeBasedReplacement) replace(population []solution, offspring []solution, populationSize int,
+	random *rand.Rand) []solution {
+	merged := mergeSolutions(population, offspring)
+	sortSolutions(merged)
+	survivors := make([]solution, 0, len(merged))
+	for i, sol := range merged {
+		if i < a.Elitism || sol.age <= a.TTL {
+			survivors = append(survivors, sol)
+		}
+	}
+	if len(survivors) > populationSize {
+		survivors = survivors[:populationSize]
+	}
+	return survivors
+}
+
+// CrowdingReplacement is restricted tournament replacement: every offspring is compared with the most similar of
+// WindowSize randomly chosen solutions, by the Jaccard distance of their POI sets, and replaces it if it is better.
+// Offspring competes only with similar solutions, so different niches of the population survive. WindowSize is
+// 10% of the population by default.
+type CrowdingReplacement struct {
+	WindowSize int
+}
+
+func (CrowdingReplacement) offspringCount(populationSize int, random *rand.Rand) int {
+	return defaultOffspringCount(populationSize, random)
+}
+
+func (c CrowdingReplacement) replace(population []solution, offspring []solution, populationSize int,
+	random *rand.Rand) []solution {
+	result := mergeSolutions(population, nil)
+	sets := poiSets(mergeSolutions(population, offspring))
+	resultSets := sets[:len(population):len(population)]
+	windowSize := c.WindowSize
+	if windowSize <= 0 {
+		windowSize = populationSize / 10
+	}
+	if windowSize < 1 {
+		windowSize = 1
+	}
+	for i, child := range offspring {
+		childSet := sets[len(population)+i]
+		if len(result) < populationSize {
+			result = append(result, child)
+			resultSets = append(resultSets, childSet)
+			continue
+		}
+		closest, closestDistance := -1, 2.0
+		for j := 0; j < windowSize; j++ {
+			candidate := random.Intn(len(result))
+			if distance := childSet.distance(resultSets[candidate]); distance < closestDistance {
+				closest, closestDistance = candidate, distance
+			}
+		}
+		if child.objectiveValue > result[closest].objectiveValue {
+			result[closest] = child
+			resultSets[closest] = childSet
+		}
+	}
+	return truncateSolutions(result, populationSize)
+}
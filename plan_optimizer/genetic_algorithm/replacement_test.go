@@ -0,0 +1,132 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomSolutions creates solutions with distinct objective values, so that they can be told apart, random ages
+// and one day visiting two of the given POIs.
+func randomSolutions(random *rand.Rand, objectives []int, pois []*POI) []solution {
+	solutions := make([]solution, len(objectives))
+	for i, objective := range objectives {
+		order := random.Perm(len(pois))
+		solutions[i] = solution{
+			itinerary:      Itinerary{Days: []Day{{Visits: []Visit{{Poi: pois[order[0]]}, {Poi: pois[order[1]]}}}}},
+			age:            random.Intn(12),
+			objectiveValue: float64(objective),
+		}
+	}
+	return solutions
+}
+
+func bestSolution(solutions []solution) solution {
+	best := solutions[0]
+	for _, sol := range solutions[1:] {
+		if sol.objectiveValue > best.objectiveValue {
+			best = sol
+		}
+	}
+	return best
+}
+
+func TestReplacementStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy ReplacementStrategy
+		// parents are discarded when there is offspring
+		discardsParents bool
+		// number of the best parents which are never replaced
+		eliteParents int
+	}{
+		{"plus", PlusReplacement{}, false, 0},
+		{"comma", CommaReplacement{OffspringRatio: 1.5}, true, 0},
+		{"steady-state", SteadyStateReplacement{Elitism: 1}, false, 1},
+		{"steady-state with elite of 3", SteadyStateReplacement{Elitism: 3, Offspring: 30}, false, 3},
+		{"age", AgeBasedReplacement{TTL: 4, Elitism: 1}, false, 0},
+		{"crowding", CrowdingReplacement{WindowSize: 3}, false, 0},
+	}
+	random := rand.New(rand.NewSource(1))
+	pois := make([]*POI, 10)
+	for i := range pois {
+		pois[i] = &POI{Name: string(rune('a' + i))}
+	}
+	for _, test := range tests {
+		for _, sizes := range []struct{ population, offspring, populationSize int }{
+			{20, 0, 20}, {20, 4, 20}, {20, 20, 20}, {20, 45, 20}, {5, 10, 20}, {30, 6, 20}, {1, 1, 1},
+		} {
+			objectives := random.Perm(sizes.population + sizes.offspring)
+			population := randomSolutions(random, objectives[:sizes.population], pois)
+			offspring := randomSolutions(random, objectives[sizes.population:], pois)
+			// the best solution is older than any age limit
+			elite := bestSolution(mergeSolutions(population, offspring)).objectiveValue
+			for i := range population {
+				if population[i].objectiveValue == elite {
+					population[i].age = 100
+				}
+			}
+			inputs := make(map[float64]bool)
+			for _, sol := range mergeSolutions(population, offspring) {
+				inputs[sol.objectiveValue] = true
+			}
+
+			result := test.strategy.replace(population, offspring, sizes.populationSize, random)
+
+			if len(result) == 0 || len(result) > sizes.populationSize {
+				t.Errorf("%s %+v: population of %d", test.name, sizes, len(result))
+				continue
+			}
+			seen := make(map[float64]bool)
+			for i, sol := range result {
+				if !inputs[sol.objectiveValue] || seen[sol.objectiveValue] {
+					t.Errorf("%s %+v: solution %f is not one of the parents and offspring", test.name, sizes, sol.objectiveValue)
+				}
+				seen[sol.objectiveValue] = true
+				if i > 0 && sol.objectiveValue > result[i-1].objectiveValue {
+					t.Errorf("%s %+v: population is not sorted from the best solution", test.name, sizes)
+				}
+			}
+			best := elite
+			if test.discardsParents && len(offspring) > 0 {
+				best = bestSolution(offspring).objectiveValue
+			}
+			// an elite filling the whole population leaves no room for offspring
+			if result[0].objectiveValue != best && test.eliteParents < sizes.populationSize {
+				t.Errorf("%s %+v: best solution %f did not survive", test.name, sizes, best)
+			}
+			parents := mergeSolutions(population, nil)
+			sortSolutions(parents)
+			for i := 0; i < test.eliteParents && i < len(parents) && i < sizes.populationSize; i++ {
+				if !seen[parents[i].objectiveValue] {
+					t.Errorf("%s %+v: elite parent %f was replaced", test.name, sizes, parents[i].objectiveValue)
+				}
+			}
+		}
+	}
+}
+
+func TestAgeBasedReplacementKeepsEliteOfOldPopulation(t *testing.T) {
+	population := make([]solution, 10)
+	for i := range population {
+		population[i] = solution{age: 9, objectiveValue: float64(i)}
+	}
+	result := AgeBasedReplacement{TTL: 8, Elitism: 1}.replace(population, nil, 10, rand.New(rand.NewSource(1)))
+	if len(result) != 1 || result[0].objectiveValue != 9 {
+		t.Errorf("population of old solutions was replaced by %+v, want only the best one", result)
+	}
+}
+
+func TestOffspringCountIsBounded(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, strategy := range []ReplacementStrategy{
+		PlusReplacement{}, CommaReplacement{}, CommaReplacement{OffspringRatio: 3}, SteadyStateReplacement{},
+		SteadyStateReplacement{Offspring: 7}, AgeBasedReplacement{}, CrowdingReplacement{},
+	} {
+		for _, populationSize := range []int{1, 10, 100, 1000} {
+			count := strategy.offspringCount(populationSize, random)
+			if count < 1 || count > 3*populationSize+7 {
+				t.Errorf("%T creates %d offspring for population of %d", strategy, count, populationSize)
+			}
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package genetic_algorithm
+
+import (
+	"fmt"
+	"time"
+)
+
+// VisitRef identifies a visit of an itinerary by the index of its day and the name of its POI.
+type VisitRef struct {
+	Day  int    `json:"day"`
+	Name string `json:"name"`
+}
+
+// TripProgress describes a trip in progress whose remainder is re-planned. Itinerary is the plan followed so far,
+// the tourist is at Position at Time of the day with index Day. Visits which ended before Time or are Done are
+// completed, Unavailable visits did not and will not take place. A visit which started before Time and ends after
+// it is in progress unless it is Done or Unavailable.
+type TripProgress struct {
+	Itinerary   *ApiItinerary
+	Day         int
+	Time        time.Time
+	Position    *POI
+	Done        []VisitRef
+	Unavailable []VisitRef
+}
+
+// SetTripProgress makes the next runs re-plan only the rest of a trip in progress. Earlier days and the visits
+// completed on the current day are kept unchanged, POIs of completed and unavailable visits are not planned again
+// and the rest of the current day starts at the current time from the current position. Visits in progress are kept
+// like completed ones and the rest of the day starts when the last of them ends. When less than
+// minimumVisitDuration minutes of the day have passed, the rest of the day starts that many minutes after its
+// beginning. It replaces the locks set by SetLocks. POIs added later are planned too. nil disables it and plans all
+// added POIs again.
+func (ga *GeneticAlgorithm) SetTripProgress(progress *TripProgress) error {
+	allPois := ga.tripPoiList()
+	if progress == nil {
+		ga.poiList = allPois
+		ga.tripPois = nil
+		ga.locks = nil
+		return nil
+	}
+	if progress.Day < 0 || progress.Day >= len(ga.daysList) || progress.Day >= len(progress.Itinerary.Days) {
+		return fmt.Errorf("current day %d is not a part of the trip", progress.Day)
+	}
+	byKey := poisByKey(allPois)
+	for dayId := 0; dayId <= progress.Day; dayId++ {
+		if progress.Itinerary.Days[dayId].DayName != ga.daysList[dayId] {
+			return fmt.Errorf("day %d is %s, not %s", dayId, progress.Itinerary.Days[dayId].DayName, ga.daysList[dayId])
+		}
+		for _, apiVisit := range progress.Itinerary.Days[dayId].Visits {
+			if _, ok := byKey[apiPoiKey(apiVisit.Poi)]; !ok && apiVisit.Type != breakType {
+				return fmt.Errorf("visit of unknown POI %q", apiVisit.Poi.Name)
+			}
+		}
+	}
+	itinerary, err := convertFromApiItinerary(progress.Itinerary, allPois)
+	if err != nil {
+		return err
+	}
+	done, err := findVisits(&itinerary, progress.Done)
+	if err != nil {
+		return err
+	}
+	unavailable, err := findVisits(&itinerary, progress.Unavailable)
+	if err != nil {
+		return err
+	}
+	for ref := range done {
+		if ref.Day > progress.Day {
+			return fmt.Errorf("visit of %q on day %d cannot be done yet", ref.Name, ref.Day)
+		}
+	}
+
+	locks := &itineraryLocks{days: make(map[int]Day), visits: make(map[int][]Visit), pois: make(map[*POI]int)}
+	excluded := make(map[*POI]bool)
+	for _, poi := range unavailable {
+		excluded[poi] = true
+	}
+	for dayId := 0; dayId <= progress.Day; dayId++ {
+		day := Day{DayNumber: dayId, DayName: ga.daysList[dayId]}
+		resume := progress.Time
+		for _, visit := range itinerary.Days[dayId].Visits {
+			ref := VisitRef{Day: dayId, Name: visit.Poi.Name}
+			completed := dayId < progress.Day || !visit.EndVisit.After(progress.Time) || done[ref] != nil
+			inProgress := !completed && visit.StartVisit.Before(progress.Time)
+			if (completed || inProgress) && unavailable[ref] == nil {
+				day.Visits = append(day.Visits, visit)
+				locks.pois[visit.Poi] = dayId
+				if inProgress {
+					resume = maxHour(resume, visit.EndVisit)
+				}
+			}
+		}
+		if dayId < progress.Day || !progress.Time.Before(ga.dayEndHour) {
+			locks.days[dayId] = day
+			continue
+		}
+		for _, visit := range day.Visits {
+			excluded[visit.Poi] = true
+		}
+		locks.start = &dayStart{dayId: dayId, visit: ga.currentPositionVisit(progress.Position, resume),
+			completed: day.Visits}
+		locks.pois[locks.start.visit.Poi] = dayId
+	}
+
+	pois := make([]*POI, 0, len(allPois))
+	for _, poi := range allPois {
+		if !excluded[poi] {
+			pois = append(pois, poi)
+		}
+	}
+	ga.tripPois = allPois
+	ga.poiList = pois
+	ga.locks = locks
+	return nil
+}
+
+// findVisits returns the POIs of the referenced visits of the itinerary.
+func findVisits(itinerary *Itinerary, refs []VisitRef) (map[VisitRef]*POI, error) {
+	found := make(map[VisitRef]*POI, len(refs))
+	for _, ref := range refs {
+		if ref.Day < 0 || ref.Day >= len(itinerary.Days) {
+			return nil, fmt.Errorf("day %d is not a part of the itinerary", ref.Day)
+		}
+		for _, visit := range itinerary.Days[ref.Day].Visits {
+			if visit.Poi.Name == ref.Name {
+				found[ref] = visit.Poi
+			}
+		}
+		if found[ref] == nil {
+			return nil, fmt.Errorf("no visit of %q on day %d", ref.Name, ref.Day)
+		}
+	}
+	return found, nil
+}
+
+// currentPositionVisit is the pinned visit at the current position which lasts from the beginning of the day until
+// the rest of the day is resumed, so that the next visit starts after travelling from the current position.
+func (ga *GeneticAlgorithm) currentPositionVisit(currentPosition *POI, resume time.Time) Visit {
+	position := &POI{
+		Name:      currentPosition.Name,
+		Lat:       currentPosition.Lat,
+		Lon:       currentPosition.Lon,
+		OpenHour:  make(map[string]time.Time),
+		CloseHour: make(map[string]time.Time),
+	}
+	for _, dayName := range ga.daysList {
+		position.OpenHour[dayName] = ga.dayBeginHour
+		position.CloseHour[dayName] = ga.dayEndHour
+	}
+	end := maxHour(resume, addMinutes(ga.dayBeginHour, minimumVisitDuration))
+	return Visit{
+		Poi:           position,
+		StartVisit:    ga.dayBeginHour,
+		EndVisit:      end,
+		VisitDuration: calculateDuration(ga.dayBeginHour, end),
+	}
+}
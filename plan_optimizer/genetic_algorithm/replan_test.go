@@ -0,0 +1,135 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+)
+
+func TestSetTripProgressKeepsAddedPois(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(4)), 6)
+	for _, poi := range pois {
+		for _, day := range testDays {
+			poi.OpenHour[day], poi.CloseHour[day] = clock(8*60), clock(20*60)
+		}
+	}
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	plan := decodeItinerary([][]*POI{pois[:3], pois[3:5]}, nil, clock(9*60), clock(19*60), testDays)
+	itinerary := convertToApiItinerary(&plan)
+
+	planned := func() map[string]bool {
+		names := make(map[string]bool)
+		for _, poi := range geneticAlgorithm.poiList {
+			names[poi.Name] = true
+		}
+		return names
+	}
+
+	// the first visit is over, the second one is not available
+	progress := &TripProgress{
+		Itinerary:   &itinerary,
+		Day:         0,
+		Time:        plan.Days[0].Visits[0].EndVisit,
+		Position:    pois[0],
+		Unavailable: []VisitRef{{Day: 0, Name: pois[1].Name}},
+	}
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	if names := planned(); len(names) != 4 || names[pois[0].Name] || names[pois[1].Name] {
+		t.Errorf("after the first visit POIs %v are planned", names)
+	}
+
+	// progress reported again later on the same trip starts from all added POIs
+	progress.Time = plan.Days[0].Visits[2].EndVisit
+	progress.Unavailable = nil
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	if names := planned(); len(names) != 3 || !names[pois[3].Name] || !names[pois[4].Name] || !names[pois[5].Name] {
+		t.Errorf("after the first day POIs %v are planned", names)
+	}
+
+	if err := geneticAlgorithm.SetTripProgress(nil); err != nil {
+		t.Fatal(err)
+	}
+	if names := planned(); len(names) != len(pois) || geneticAlgorithm.locks != nil {
+		t.Errorf("without trip progress POIs %v are planned", names)
+	}
+	if err := geneticAlgorithm.SetTripProgress(nil); err != nil || len(geneticAlgorithm.poiList) != len(pois) {
+		t.Errorf("disabling trip progress twice leaves %d POIs, error %v", len(geneticAlgorithm.poiList), err)
+	}
+}
+
+func TestSetTripProgressKeepsPoisAddedLater(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(4)), 6)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois[:5] {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	plan := decodeItinerary([][]*POI{pois[:2]}, nil, clock(9*60), clock(19*60), testDays)
+	itinerary := convertToApiItinerary(&plan)
+	progress := &TripProgress{Itinerary: &itinerary, Time: plan.Days[0].Visits[1].EndVisit, Position: pois[1]}
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	geneticAlgorithm.AddPoi(pois[5])
+	if !containsPoi(geneticAlgorithm.poiList, pois[5]) {
+		t.Errorf("POI added during the trip is not planned")
+	}
+	if err := geneticAlgorithm.SetTripProgress(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(geneticAlgorithm.poiList) != len(pois) || !containsPoi(geneticAlgorithm.poiList, pois[5]) {
+		t.Errorf("without trip progress %d POIs are planned, not all %d", len(geneticAlgorithm.poiList), len(pois))
+	}
+}
+
+func TestReplanKeepsVisitInProgress(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(4)), 8)
+	for _, poi := range pois {
+		for _, day := range testDays {
+			poi.OpenHour[day], poi.CloseHour[day] = clock(8*60), clock(20*60)
+		}
+	}
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	geneticAlgorithm.SetRunParameters(30, 20, 8)
+	geneticAlgorithm.SetSeed(1)
+	plan := decodeItinerary([][]*POI{pois[:3], pois[3:5]}, nil, clock(9*60), clock(19*60), testDays)
+	itinerary := convertToApiItinerary(&plan)
+
+	// the tourist is in the middle of the second visit
+	inProgress := plan.Days[0].Visits[1]
+	progress := &TripProgress{
+		Itinerary: &itinerary,
+		Time:      addMinutes(inProgress.StartVisit, 30),
+		Position:  inProgress.Poi,
+	}
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	if containsPoi(geneticAlgorithm.poiList, inProgress.Poi) {
+		t.Errorf("POI of the visit in progress is planned again")
+	}
+	result, _ := geneticAlgorithm.Solve(context.Background())
+	replanned, err := convertFromApiItinerary(&result, pois)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := replanned.Days[0]
+	if len(day.Visits) < 2 || !sameVisits(day.Visits[:2], plan.Days[0].Visits[:2]) {
+		t.Fatalf("first day starts with %+v, not the completed visit and the visit in progress", day.Visits)
+	}
+	for _, visit := range day.Visits[2:] {
+		if visit.StartVisit.Before(inProgress.EndVisit) {
+			t.Errorf("visit of %s starts at %s, before the visit in progress ends", visit.Poi.Name,
+				visit.StartVisit.Format("15:04"))
+		}
+	}
+}
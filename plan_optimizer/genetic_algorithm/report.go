@@ -0,0 +1,161 @@
+package genetic_algorithm
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type OperatorStats struct {
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"` // crossover, mutation or local-search
+	Applied     int     `json:"applied"`
+	Improved    int     `json:"improved"` // offspring better than the better of its parents, or solution improved by the move
+	SuccessRate float64 `json:"successRate"`
+}
+
+// GenerationStats describes the population after one generation of the genetic algorithm.
+type GenerationStats struct {
+	Generation    int     `json:"generation"`
+	Best          float64 `json:"best"`
+	Mean          float64 `json:"mean"`
+	Worst         float64 `json:"worst"`
+	FeasibleRatio float64 `json:"feasibleRatio"` // share of solutions which do not fail any constraint
+	Diversity     float64 `json:"diversity"`
+}
+
+// RunReport describes the last run of the algorithm. Generations and ConstraintFailures are filled by the
+// genetic algorithm only, with the island model generations are recorded at every migration. Phases holds the wall
+// time of the phases of the run in milliseconds, with the island model it is summed over all islands.
+type RunReport struct {
+	Seed               int64              `json:"seed"`
+	StopReason         string             `json:"stopReason,omitempty"` // which termination condition stopped the run
+	Generations        []GenerationStats  `json:"generations,omitempty"`
+	ConstraintFailures map[string]int     `json:"constraintFailures,omitempty"` // failures in evaluated solutions
+	Phases             map[string]float64 `json:"phases,omitempty"`
+	Restarts           int                `json:"restarts,omitempty"` // partial restarts of converged populations
+	Operators          []OperatorStats    `json:"operators"`
+}
+
+func (p *problem) recordOperator(kind string, name string, improved bool) {
+	key := kind + ":" + name
+	stats, ok := p.operatorStats[key]
+	if !ok {
+		stats = &OperatorStats{Name: name, Kind: kind}
+		p.operatorStats[key] = stats
+	}
+	stats.Applied++
+	if improved {
+		stats.Improved++
+	}
+}
+
+// Report returns statistics of the last run of the solver.
+func (p *problem) Report() RunReport {
+	report := RunReport{
+		Seed:               p.runSeed,
+		StopReason:         p.stopReason,
+		Generations:        p.generations,
+		ConstraintFailures: p.constraintFailures,
+		Phases:             make(map[string]float64, len(p.phases)),
+		Restarts:           p.restarts,
+		Operators:          make([]OperatorStats, 0, len(p.operatorStats)),
+	}
+	for phase, duration := range p.phases {
+		report.Phases[phase] = float64(duration) / float64(time.Millisecond)
+	}
+	for _, stats := range p.operatorStats {
+		operator := *stats
+		if operator.Applied > 0 {
+			operator.SuccessRate = float64(operator.Improved) / float64(operator.Applied)
+		}
+		report.Operators = append(report.Operators, operator)
+	}
+	sort.Slice(report.Operators, func(i, j int) bool {
+		if report.Operators[i].Kind != report.Operators[j].Kind {
+			return report.Operators[i].Kind < report.Operators[j].Kind
+		}
+		return report.Operators[i].Name < report.Operators[j].Name
+	})
+	return report
+}
+
+func (p *problem) recordGeneration(generation int, population []solution) {
+	stats := GenerationStats{Generation: generation, Diversity: populationDiversity(population)}
+	if len(population) > 0 {
+		stats.Best = population[0].objectiveValue
+		stats.Worst = population[0].objectiveValue
+	}
+	feasible := 0
+	for _, sol := range population {
+		stats.Mean += sol.objectiveValue
+		if sol.objectiveValue > stats.Best {
+			stats.Best = sol.objectiveValue
+		}
+		if sol.objectiveValue < stats.Worst {
+			stats.Worst = sol.objectiveValue
+		}
+		if sol.failedConstraints == 0 {
+			feasible++
+		}
+	}
+	if len(population) > 0 {
+		stats.Mean /= float64(len(population))
+		stats.FeasibleRatio = float64(feasible) / float64(len(population))
+	}
+	p.generations = append(p.generations, stats)
+}
+
+// mergeStats adds the statistics of another run, e.g. of an island, to the statistics of this run.
+func (p *problem) mergeStats(other *problem) {
+	for key, stats := range other.operatorStats {
+		total, ok := p.operatorStats[key]
+		if !ok {
+			total = &OperatorStats{Name: stats.Name, Kind: stats.Kind}
+			p.operatorStats[key] = total
+		}
+		total.Applied += stats.Applied
+		total.Improved += stats.Improved
+	}
+	for name, count := range other.constraintFailures {
+		p.constraintFailures[name] += count
+	}
+	for phase, duration := range other.phases {
+		p.phases[phase] += duration
+	}
+	p.restarts += other.restarts
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r RunReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes the statistics of every generation as CSV with a header row.
+func (r RunReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	header := []string{"generation", "best", "mean", "worst", "feasible_ratio", "diversity"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, stats := range r.Generations {
+		record := []string{
+			strconv.Itoa(stats.Generation),
+			strconv.FormatFloat(stats.Best, 'f', 10, 64),
+			strconv.FormatFloat(stats.Mean, 'f', 10, 64),
+			strconv.FormatFloat(stats.Worst, 'f', 10, 64),
+			strconv.FormatFloat(stats.FeasibleRatio, 'f', 4, 64),
+			strconv.FormatFloat(stats.Diversity, 'f', 4, 64),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,129 @@
+package genetic_algorithm
+
+import "time"
+
+const minimumVisitDuration = 60
+const defaultVisitDuration = 120
+
+// decodeItinerary turns an ordered list of POIs for every day into a feasible itinerary. It is deterministic:
+// every visit starts as soon as the previous one has ended, the POI has been reached and it is open, and lasts
+// its preferred duration as long as opening hours and the end of the day allow. POIs without a preferred duration
+// get defaultVisitDuration. POIs which cannot be visited for at least minimumVisitDuration minutes are dropped,
+// so the result never violates time windows, travel times or the minimum visit duration.
+func decodeItinerary(order [][]*POI, preferredDurations map[*POI]int, dayBeginHour, dayEndHour time.Time,
+	daysList []string) Itinerary {
+	itinerary := Itinerary{
+		Days:         make([]Day, len(daysList)),
+		DayBeginHour: dayBeginHour,
+		DayEndHour:   dayEndHour,
+	}
+	for dayNumber, dayName := range daysList {
+		var sequence []*POI
+		if dayNumber < len(order) {
+			sequence = order[dayNumber]
+		}
+		itinerary.Days[dayNumber] = decodeDay(sequence, preferredDurations, Day{DayNumber: dayNumber, DayName: dayName},
+			dayBeginHour, dayEndHour)
+	}
+	return itinerary
+}
+
+// decodeDay schedules the sequence on a day with the number, the name, the breaks and the fixed visits of template.
+func decodeDay(sequence []*POI, preferredDurations map[*POI]int, template Day, dayBeginHour, dayEndHour time.Time) Day {
+	day := template.withoutVisits()
+	day.Visits = make([]Visit, 0, len(sequence))
+	for _, poi := range sequence {
+		if visit, ok := scheduleNextVisit(&day, poi, preferredDurations[poi], dayBeginHour, dayEndHour); ok {
+			day.Visits = append(day.Visits, visit)
+		}
+	}
+	return day
+}
+
+// scheduleNextVisit computes the visit of the POI after the last visit of the day in the way described by
+// decodeItinerary, delayed to make room for the breaks of the day. Fixed visits keep their times and other visits
+// end early enough to reach the fixed visits which are not scheduled yet. ok is false if the visit cannot last
+// minimumVisitDuration minutes or a fixed visit cannot be reached in time.
+func scheduleNextVisit(day *Day, poi *POI, preferredDuration int, dayBeginHour, dayEndHour time.Time) (Visit, bool) {
+	arrival := dayBeginHour
+	if len(day.Visits) > 0 {
+		prevVisit := day.Visits[len(day.Visits)-1]
+		arrival = addMinutes(prevVisit.EndVisit, transport(prevVisit.Poi, poi))
+	}
+	if fixed, ok := day.fixedVisit(poi); ok {
+		if arrival.After(fixed.StartVisit) {
+			return Visit{}, false
+		}
+		return fixed, true
+	}
+	latestEnd := day.latestEnd(poi, arrival, dayEndHour)
+	visit, ok := scheduleVisit(day.DayName, poi, preferredDuration, arrival, dayBeginHour, latestEnd)
+	if !ok || len(day.breaks) == 0 {
+		return visit, ok
+	}
+	return makeRoomForBreaks(day, visit, preferredDuration, arrival, dayBeginHour, latestEnd)
+}
+
+// withoutVisits returns an empty day with the number, the name, the breaks and the fixed visits of the day.
+func (day Day) withoutVisits() Day {
+	return Day{DayNumber: day.DayNumber, DayName: day.DayName, breaks: day.breaks, fixed: day.fixed}
+}
+
+func (day *Day) fixedVisit(poi *POI) (Visit, bool) {
+	for _, visit := range day.fixed {
+		if visit.Poi == poi {
+			return visit, true
+		}
+	}
+	return Visit{}, false
+}
+
+// latestEnd is the time by which a visit of the POI starting after arrival has to end, so that the fixed visits
+// which start later and are not scheduled yet can still be reached.
+func (day *Day) latestEnd(poi *POI, arrival, dayEndHour time.Time) time.Time {
+	end := dayEndHour
+	for _, fixed := range day.fixed {
+		if fixed.StartVisit.Before(arrival) || dayContainsPoi(*day, fixed.Poi) {
+			continue
+		}
+		end = minHour(end, subtractMinutes(fixed.StartVisit, transport(poi, fixed.Poi)))
+	}
+	return end
+}
+
+// scheduleVisit computes the visit of the POI which starts as soon as it is open after arrival.
+func scheduleVisit(dayName string, poi *POI, preferredDuration int, arrival, dayBeginHour,
+	dayEndHour time.Time) (Visit, bool) {
+	startVisit := maxHour(dayBeginHour, poi.OpenHour[dayName], arrival)
+	duration := preferredDuration
+	if duration == 0 {
+		duration = defaultVisitDuration
+	} else if duration < minimumVisitDuration {
+		duration = minimumVisitDuration
+	}
+	endVisit := minHour(addMinutes(startVisit, duration), poi.CloseHour[dayName], dayEndHour)
+	if calculateDuration(startVisit, endVisit) < minimumVisitDuration {
+		return Visit{}, false
+	}
+	return Visit{
+		Poi:           poi,
+		StartVisit:    startVisit,
+		EndVisit:      endVisit,
+		VisitDuration: calculateDuration(startVisit, endVisit),
+	}, true
+}
+
+// repairSchedule recomputes the times of all visits for their current order with decodeDay.
+func repairSchedule(itinerary *Itinerary) {
+	for i := range itinerary.Days {
+		repairDaySchedule(&itinerary.Days[i], itinerary.DayBeginHour, itinerary.DayEndHour)
+	}
+}
+
+func repairDaySchedule(day *Day, dayBeginHour, dayEndHour time.Time) {
+	durations := make(map[*POI]int, len(day.Visits))
+	for _, visit := range day.Visits {
+		durations[visit.Poi] = visit.VisitDuration
+	}
+	*day = decodeDay(daySequence(*day), durations, *day, dayBeginHour, dayEndHour)
+}
@@ -0,0 +1,83 @@
+package genetic_algorithm
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestDecodedItinerariesAreFeasible(t *testing.T) {
+	random := rand.New(rand.NewSource(16))
+	pois := randomPois(random, 30)
+	constraints := []struct {
+		name       string
+		constraint Constraint
+	}{
+		{"PoiOpenedDuringVisit", &PoiOpenedDuringVisit{}},
+		{"TimeDifferenceBetweenPoints", &TimeDifferenceBetweenPoints{}},
+		{"MinimumTimeInPoi", &MinimumTimeInPoi{}},
+		{"VisitsWithinDayLimits", &VisitsWithinDayLimits{}},
+	}
+
+	for i := 0; i < 300; i++ {
+		shuffled := append([]*POI(nil), pois...)
+		random.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		split := random.Intn(len(shuffled))
+		order := [][]*POI{shuffled[:split], shuffled[split:]}
+		durations := make(map[*POI]int)
+		for _, poi := range pois {
+			durations[poi] = random.Intn(241)
+		}
+
+		itinerary := decodeItinerary(order, durations, clock(9*60), clock(19*60), testDays)
+		for _, c := range constraints {
+			failed := ConstraintsCount{}
+			c.constraint.execute(&itinerary, &failed)
+			if failed.failedConstraints > 0 {
+				t.Fatalf("decoded order %d fails %s", i, c.name)
+			}
+		}
+		for dayId, day := range itinerary.Days {
+			if !isSubsequence(daySequence(day), order[dayId]) {
+				t.Fatalf("decoding changed the order of day %d", dayId)
+			}
+		}
+	}
+}
+
+// isSubsequence checks if sequence keeps the order of the POIs of order, possibly without some of them.
+func isSubsequence(sequence, order []*POI) bool {
+	i := 0
+	for _, poi := range order {
+		if i < len(sequence) && sequence[i] == poi {
+			i++
+		}
+	}
+	return i == len(sequence)
+}
+
+func TestDecodingDropsVisitsWhichCannotFit(t *testing.T) {
+	pois := breakTestPois(8)
+	// closes before the day begins
+	pois[1].CloseHour[testDays[0]] = clock(8*60 + 30)
+	// open for less than minimumVisitDuration minutes
+	pois[2].OpenHour[testDays[0]], pois[2].CloseHour[testDays[0]] = clock(10*60), clock(10*60+30)
+
+	itinerary := decodeItinerary([][]*POI{pois}, nil, clock(9*60), clock(19*60), testDays)
+	sequence := daySequence(itinerary.Days[0])
+	for _, poi := range sequence {
+		if poi == pois[1] || poi == pois[2] {
+			t.Errorf("%s is visited although it cannot be open for an hour during the day", poi.Name)
+		}
+	}
+	// the ten hours of the day fit at most five visits of two hours, the day ends with the last of them
+	if len(sequence) < 3 || len(sequence) > 5 || sequence[0] != pois[0] {
+		t.Errorf("day visits %d POIs", len(sequence))
+	}
+	last := itinerary.Days[0].Visits[len(sequence)-1]
+	if last.EndVisit.After(clock(19 * 60)) {
+		t.Errorf("last visit ends at %s", last.EndVisit.Format("15:04"))
+	}
+	if !isSubsequence(sequence, pois) {
+		t.Errorf("decoding changed the order of the POIs")
+	}
+}
@@ -0,0 +1,211 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// SelectionStrategy picks pairs of parents for crossover from the current population.
+type SelectionStrategy interface {
+	selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution
+}
+
+// shiftedFitness moves all objective values above zero, so that fitness proportional methods also work when the
+// penalty for failed constraints makes objectives negative. The worst solution keeps a small non-zero chance.
+func shiftedFitness(population []solution) []float64 {
+	fitness := make([]float64, len(population))
+	if len(population) == 0 {
+		return fitness
+	}
+	minValue, maxValue := population[0].objectiveValue, population[0].objectiveValue
+	for _, sol := range population {
+		if sol.objectiveValue < minValue {
+			minValue = sol.objectiveValue
+		}
+		if sol.objectiveValue > maxValue {
+			maxValue = sol.objectiveValue
+		}
+	}
+	epsilon := (maxValue - minValue) * 0.01
+	if epsilon == 0 {
+		epsilon = 1.0
+	}
+	for i, sol := range population {
+		fitness[i] = sol.objectiveValue - minValue + epsilon
+	}
+	return fitness
+}
+
+func pickByWeight(weights []float64, totalWeight float64, random *rand.Rand) int {
+	randomValue := random.Float64() * totalWeight
+	accumulated := 0.0
+	for i, weight := range weights {
+		accumulated += weight
+		if accumulated >= randomValue {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
+
+func selectByWeights(population []solution, weights []float64, numberOfPairs int, random *rand.Rand) [][]solution {
+	totalWeight := 0.0
+	for _, weight := range weights {
+		totalWeight += weight
+	}
+	result := make([][]solution, numberOfPairs)
+	for i := 0; i < numberOfPairs; i++ {
+		result[i] = []solution{population[pickByWeight(weights, totalWeight, random)],
+			population[pickByWeight(weights, totalWeight, random)]}
+	}
+	return result
+}
+
+// RouletteWheelSelection picks parents with probability proportional to their shifted objective value.
+type RouletteWheelSelection struct{}
+
+func (RouletteWheelSelection) selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution {
+	if len(population) == 0 {
+		return nil
+	}
+	return selectByWeights(population, shiftedFitness(population), numberOfPairs, random)
+}
+
+// TournamentSelection picks the best of Size randomly drawn solutions for every parent. Bigger tournaments mean
+// stronger selection pressure.
+type TournamentSelection struct {
+	Size int
+}
+
+func (t TournamentSelection) selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution {
+	if len(population) == 0 {
+		return nil
+	}
+	size := t.Size
+	if size < 1 {
+		size = 2
+	}
+	tournament := func() solution {
+		best := population[random.Intn(len(population))]
+		for i := 1; i < size; i++ {
+			if candidate := population[random.Intn(len(population))]; candidate.objectiveValue > best.objectiveValue {
+				best = candidate
+			}
+		}
+		return best
+	}
+	result := make([][]solution, numberOfPairs)
+	for i := 0; i < numberOfPairs; i++ {
+		result[i] = []solution{tournament(), tournament()}
+	}
+	return result
+}
+
+// LinearRankSelection picks parents with probability depending only on their rank. Pressure between 1 and 2 is the
+// expected number of times the best solution is selected per population size draws, 1 means uniform selection.
+type LinearRankSelection struct {
+	Pressure float64
+}
+
+func (l LinearRankSelection) selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution {
+	n := len(population)
+	if n == 0 {
+		return nil
+	}
+	pressure := l.Pressure
+	if pressure < 1.0 || pressure > 2.0 {
+		pressure = 1.5
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	// worst solution first, so that the index is the rank
+	sort.SliceStable(order, func(i, j int) bool {
+		return population[order[i]].objectiveValue < population[order[j]].objectiveValue
+	})
+	weights := make([]float64, n)
+	for rank, index := range order {
+		if n == 1 {
+			weights[index] = 1.0
+		} else {
+			weights[index] = (2.0-pressure)/float64(n) + 2.0*float64(rank)*(pressure-1.0)/float64(n*(n-1))
+		}
+	}
+	return selectByWeights(population, weights, numberOfPairs, random)
+}
+
+// StochasticUniversalSampling places equally spaced pointers on the roulette wheel, so the number of times each
+// solution is selected stays close to its expected value.
+type StochasticUniversalSampling struct{}
+
+func (StochasticUniversalSampling) selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution {
+	if len(population) == 0 {
+		return nil
+	}
+	fitness := shiftedFitness(population)
+	totalFitness := 0.0
+	for _, f := range fitness {
+		totalFitness += f
+	}
+	numberOfParents := 2 * numberOfPairs
+	distance := totalFitness / float64(numberOfParents)
+	pointer := random.Float64() * distance
+
+	parents := make([]solution, 0, numberOfParents)
+	accumulated := fitness[0]
+	index := 0
+	for len(parents) < numberOfParents {
+		for accumulated < pointer && index < len(population)-1 {
+			index++
+			accumulated += fitness[index]
+		}
+		parents = append(parents, population[index])
+		pointer += distance
+	}
+	// pointers visit the population in order, shuffle so that neighbours are not always paired
+	random.Shuffle(len(parents), func(i, j int) {
+		parents[i], parents[j] = parents[j], parents[i]
+	})
+	result := make([][]solution, numberOfPairs)
+	for i := 0; i < numberOfPairs; i++ {
+		result[i] = parents[2*i : 2*i+2]
+	}
+	return result
+}
+
+// FitnessSharingSelection is roulette wheel selection on shared fitness: the shifted objective value of a solution
+// is divided by the number of solutions in its niche, so that crowded regions of the search space lose selection
+// pressure. Solutions closer than Radius in the Jaccard distance of their POI sets share fitness with weight
+// 1-(distance/Radius)^Alpha. Radius is 0.5 and Alpha 1 by default.
+type FitnessSharingSelection struct {
+	Radius float64
+	Alpha  float64
+}
+
+func (f FitnessSharingSelection) selectParentsPairs(population []solution, numberOfPairs int, random *rand.Rand) [][]solution {
+	if len(population) == 0 {
+		return nil
+	}
+	radius := f.Radius
+	if radius <= 0 {
+		radius = 0.5
+	}
+	alpha := f.Alpha
+	if alpha <= 0 {
+		alpha = 1.0
+	}
+	fitness := shiftedFitness(population)
+	sets := poiSets(population)
+	for i := range population {
+		niche := 0.0
+		for j := range population {
+			if distance := sets[i].distance(sets[j]); distance < radius {
+				niche += 1.0 - math.Pow(distance/radius, alpha)
+			}
+		}
+		fitness[i] /= niche
+	}
+	return selectByWeights(population, fitness, numberOfPairs, random)
+}
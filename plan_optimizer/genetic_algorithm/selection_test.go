@@ -0,0 +1,131 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func populationWithObjectives(objectives ...float64) []solution {
+	population := make([]solution, len(objectives))
+	for i, objective := range objectives {
+		population[i] = solution{objectiveValue: objective}
+	}
+	return population
+}
+
+// selectionShares returns how often each solution of the population, told apart by its objective value, was picked
+// as a parent, divided by the number of parents.
+func selectionShares(t *testing.T, population []solution, pairs [][]solution) []float64 {
+	shares := make([]float64, len(population))
+	parents := 0
+	for _, pair := range pairs {
+		if len(pair) != 2 {
+			t.Fatalf("pair has %d parents", len(pair))
+		}
+		for _, parent := range pair {
+			found := false
+			for i := range population {
+				if population[i].objectiveValue == parent.objectiveValue {
+					shares[i]++
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("parent with objective %f is not in the population", parent.objectiveValue)
+			}
+			parents++
+		}
+	}
+	for i := range shares {
+		shares[i] /= float64(parents)
+	}
+	return shares
+}
+
+func TestTournamentSelectionPressureGrowsWithSize(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	population := populationWithObjectives(5, 3, 9, 0, 1, 8, 2, 7, 4, 6)
+	best := 2
+	previous := 0.0
+	for _, size := range []int{1, 2, 4, 8} {
+		shares := selectionShares(t, population, TournamentSelection{Size: size}.selectParentsPairs(population, 50000, random))
+		// the best solution wins unless it is missing from all size draws with replacement
+		expected := 1.0 - math.Pow(0.9, float64(size))
+		if math.Abs(shares[best]-expected) > 0.01 {
+			t.Errorf("tournament of %d: best solution won %.3f of draws, expected %.3f", size, shares[best], expected)
+		}
+		if shares[best] <= previous {
+			t.Errorf("tournament of %d: best solution won %.3f of draws, not more than %.3f with smaller tournaments",
+				size, shares[best], previous)
+		}
+		previous = shares[best]
+	}
+}
+
+func TestLinearRankSelectionMatchesPressure(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	// objectives are unordered and negative, only the rank matters
+	population := populationWithObjectives(-3, -100, 7, 2, -50)
+	ranks := []int{2, 0, 4, 3, 1}
+	n := float64(len(population))
+	for _, pressure := range []float64{1.0, 1.5, 2.0} {
+		shares := selectionShares(t, population, LinearRankSelection{Pressure: pressure}.selectParentsPairs(population, 50000, random))
+		for i, rank := range ranks {
+			expected := (2.0-pressure)/n + 2.0*float64(rank)*(pressure-1.0)/(n*(n-1))
+			if math.Abs(shares[i]-expected) > 0.01 {
+				t.Errorf("pressure %.1f: rank %d selected %.3f of draws, expected %.3f", pressure, rank, shares[i], expected)
+			}
+		}
+	}
+}
+
+func TestStochasticUniversalSamplingSpreadIsBounded(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	population := populationWithObjectives(10, -20, 35, 0, 80, -5, 12, 50)
+	fitness := shiftedFitness(population)
+	totalFitness := 0.0
+	for _, f := range fitness {
+		totalFitness += f
+	}
+	for _, numberOfPairs := range []int{1, 4, 10, 33} {
+		parents := float64(2 * numberOfPairs)
+		for run := 0; run < 200; run++ {
+			shares := selectionShares(t, population, StochasticUniversalSampling{}.selectParentsPairs(population, numberOfPairs, random))
+			for i := range population {
+				// equally spaced pointers select every solution the expected number of times rounded down or up
+				count := shares[i] * parents
+				expected := parents * fitness[i] / totalFitness
+				if count < math.Floor(expected)-1e-9 || count > math.Ceil(expected)+1e-9 {
+					t.Fatalf("%d parents: solution %d selected %.0f times, expected %.2f", int(parents), i, count, expected)
+				}
+			}
+		}
+	}
+}
+
+func TestRouletteWheelSelectionWithNegativeObjectives(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	// penalised solutions have large negative objectives
+	population := populationWithObjectives(-1000, -510, -10, -250)
+	fitness := shiftedFitness(population)
+	totalFitness := 0.0
+	for i, f := range fitness {
+		if f <= 0 {
+			t.Fatalf("solution %d has shifted fitness %f", i, f)
+		}
+		totalFitness += f
+	}
+	shares := selectionShares(t, population, RouletteWheelSelection{}.selectParentsPairs(population, 50000, random))
+	for i := range population {
+		if expected := fitness[i] / totalFitness; math.Abs(shares[i]-expected) > 0.01 {
+			t.Errorf("solution %d selected %.3f of draws, expected %.3f", i, shares[i], expected)
+		}
+	}
+	if shares[0] == 0 {
+		t.Errorf("the worst solution is never selected")
+	}
+	if shares[2] <= shares[3] || shares[3] <= shares[1] || shares[1] <= shares[0] {
+		t.Errorf("selection shares %v do not follow the objectives", shares)
+	}
+}
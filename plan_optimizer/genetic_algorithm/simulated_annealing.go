@@ -0,0 +1,73 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// SimulatedAnnealing changes a random itinerary with one random mutation operator at a time. Better itineraries
+// are always accepted and worse ones with probability exp(delta/temperature), the temperature is multiplied by
+// the cooling rate after every step.
+type SimulatedAnnealing struct {
+	problem
+	iterations         int
+	initialTemperature float64
+	coolingRate        float64
+}
+
+// DefaultCoolingRate is the cooling rate of simulated annealing unless SetTemperature changes it.
+const DefaultCoolingRate = 0.9995
+
+func CreateSimulatedAnnealing(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) *SimulatedAnnealing {
+	return &SimulatedAnnealing{
+		problem: createProblem(dayBeginHour, dayEndHour, daysList, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier),
+		iterations:         20000,
+		initialTemperature: poiMultiplier,
+		coolingRate:        DefaultCoolingRate,
+	}
+}
+
+// SetIterations sets the number of steps, 20000 by default.
+func (sa *SimulatedAnnealing) SetIterations(iterations int) {
+	sa.iterations = iterations
+}
+
+// SetTemperature sets the initial temperature, by default equal to the value of one POI in the objective, and the
+// cooling rate between 0 and 1, DefaultCoolingRate by default.
+func (sa *SimulatedAnnealing) SetTemperature(initialTemperature float64, coolingRate float64) {
+	sa.initialTemperature = initialTemperature
+	sa.coolingRate = coolingRate
+}
+
+func (sa *SimulatedAnnealing) Solve(ctx context.Context) (ApiItinerary, RunReport) {
+	sa.startRun()
+	operators := AllMutationOperators()
+
+	current := sa.randomSolution()
+	best := current
+	temperature := sa.initialTemperature
+
+	for i := 0; i < sa.iterations && ctx.Err() == nil; i++ {
+		operator := operators[sa.random.Intn(len(operators))]
+		candidate := solution{itinerary: copyItinerary(&current.itinerary)}
+		if !operator.mutate(&candidate, sa.poiList, sa.random) {
+			continue
+		}
+		candidate.objectiveValue, _ = sa.evaluateItinerary(&candidate.itinerary)
+		delta := candidate.objectiveValue - current.objectiveValue
+		sa.recordOperator("mutation", operator.name(), delta > 0)
+
+		if delta >= 0 || (temperature > 0 && sa.random.Float64() < math.Exp(delta/temperature)) {
+			current = candidate
+			if current.objectiveValue > best.objectiveValue {
+				best = current
+			}
+		}
+		temperature *= sa.coolingRate
+	}
+	sa.stopReason = iterationsStopReason(ctx)
+	return sa.result(best.itinerary), sa.Report()
+}
@@ -0,0 +1,171 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Solver finds an itinerary for the added POIs. Every solver uses the same constraint chain and objective function,
+// so their results can be compared. Solve stops early when the context is cancelled and returns the best itinerary
+// found so far together with a report of the run.
+type Solver interface {
+	AddPoi(p *POI)
+	SetAccommodation(p *POI)
+	SetSeed(seed int64)
+	Solve(ctx context.Context) (ApiItinerary, RunReport)
+}
+
+// problem holds the data and the model shared by all solvers.
+type problem struct {
+	poiList                []*POI
+	tripPois               []*POI // all added POIs while SetTripProgress excludes some of them from poiList
+	constraints            Constraint
+	dayBeginHour           time.Time
+	dayEndHour             time.Time
+	daysList               []string
+	poiMultiplier          float64
+	penaltyMultiplier      float64
+	satisfactionMultiplier float64
+	accommodation          *POI
+	seed                   int64
+	stability              *stabilityReference
+	breaks                 []Break
+	random                 *rand.Rand // generator of the current run, only used by the goroutine running it
+
+	// statistics of the last run, see RunReport
+	runSeed            int64
+	operatorStats      map[string]*OperatorStats
+	stopReason         string
+	generations        []GenerationStats
+	constraintFailures map[string]int
+	phases             map[string]time.Duration
+	restarts           int
+}
+
+func createProblem(dayBeginHour, dayEndHour time.Time, daysList []string, poiMultiplier float64,
+	penaltyMultiplier float64, satisfactionMultiplier float64) problem {
+	visitsWithinDayLimits := &VisitsWithinDayLimits{}
+
+	timeDifferenceBetweenPoints := &TimeDifferenceBetweenPoints{}
+	timeDifferenceBetweenPoints.setNext(visitsWithinDayLimits)
+
+	poiOpenedDuringVisit := &PoiOpenedDuringVisit{}
+	poiOpenedDuringVisit.setNext(timeDifferenceBetweenPoints)
+
+	minimumTimeInPoi := &MinimumTimeInPoi{}
+	minimumTimeInPoi.setNext(poiOpenedDuringVisit)
+
+	originalPoi := &OriginalPoi{}
+	originalPoi.setNext(minimumTimeInPoi)
+
+	lockedParts := &LockedParts{}
+	lockedParts.setNext(originalPoi)
+
+	breaksTaken := &BreaksTaken{}
+	breaksTaken.setNext(lockedParts)
+
+	return problem{
+		constraints:            breaksTaken,
+		dayBeginHour:           dayBeginHour,
+		dayEndHour:             dayEndHour,
+		daysList:               daysList,
+		poiMultiplier:          poiMultiplier,
+		penaltyMultiplier:      penaltyMultiplier,
+		satisfactionMultiplier: satisfactionMultiplier,
+		operatorStats:          make(map[string]*OperatorStats),
+	}
+}
+
+// SetSeed sets the seed of the random number generator used by Solve, so that runs can be repeated: runs with the
+// same seed, POIs and settings return the same itinerary on any number of CPUs, unless they are stopped by a time
+// budget or cancelled. By default every run uses a new seed based on the current time, it is reported in RunReport.
+func (p *problem) SetSeed(seed int64) {
+	p.seed = seed
+}
+
+// startRun creates the random number generator of the run from its seed and clears the statistics of the last run.
+func (p *problem) startRun() {
+	p.runSeed = p.seed
+	if p.runSeed == 0 {
+		p.runSeed = time.Now().UnixNano()
+	}
+	p.random = rand.New(rand.NewSource(p.runSeed))
+	p.resetStats()
+}
+
+// splitRandom derives count generators from random, one for every parallel task or island. Tasks get them by their
+// index, so the results do not depend on how the tasks are scheduled on goroutines.
+func splitRandom(random *rand.Rand, count int) []*rand.Rand {
+	randoms := make([]*rand.Rand, count)
+	for i := range randoms {
+		randoms[i] = rand.New(rand.NewSource(random.Int63()))
+	}
+	return randoms
+}
+
+func (p *problem) resetStats() {
+	p.operatorStats = make(map[string]*OperatorStats)
+	p.stopReason = ""
+	p.generations = nil
+	p.constraintFailures = make(map[string]int)
+	p.phases = make(map[string]time.Duration)
+	p.restarts = 0
+}
+
+// timePhase adds the time since start to the wall time of the phase.
+func (p *problem) timePhase(phase string, start time.Time) {
+	p.phases[phase] += time.Since(start)
+}
+
+func (p *problem) countConstraintFailures(failed *ConstraintsCount) {
+	for _, name := range failed.names {
+		p.constraintFailures[name]++
+	}
+}
+
+// AddPoi adds a POI to plan. While the rest of a trip is re-planned, it is also kept for SetTripProgress.
+func (p *problem) AddPoi(poi *POI) {
+	p.poiList = append(p.poiList, poi)
+	if p.tripPois != nil {
+		p.tripPois = append(p.tripPois, poi)
+	}
+}
+
+// tripPoiList returns all added POIs, including the ones SetTripProgress does not plan again.
+func (p *problem) tripPoiList() []*POI {
+	if p.tripPois != nil {
+		return p.tripPois
+	}
+	return p.poiList
+}
+
+// SetAccommodation sets the place where the tourist starts and ends each day. It is only used to describe travel
+// legs in the returned itinerary.
+func (p *problem) SetAccommodation(poi *POI) {
+	p.accommodation = poi
+}
+
+// evaluateItinerary returns the objective value of the itinerary and the number of failed constraints.
+func (p *problem) evaluateItinerary(itinerary *Itinerary) (float64, int) {
+	failedConstraints := ConstraintsCount{}
+	p.constraints.execute(itinerary, &failedConstraints)
+	sol := solution{itinerary: *itinerary}
+	objectiveFunction(&sol, failedConstraints.failedConstraints, p.poiMultiplier, p.penaltyMultiplier,
+		p.satisfactionMultiplier, p.stabilityPenalty(itinerary))
+	return sol.objectiveValue, failedConstraints.failedConstraints
+}
+
+func (p *problem) randomSolution() solution {
+	sol := solution{itinerary: GenerateRandomItinerary(p.poiList, p.dayBeginHour, p.dayEndHour, p.daysList,
+		p.random)}
+	p.scheduleBreaks(&sol.itinerary)
+	sol.objectiveValue, _ = p.evaluateItinerary(&sol.itinerary)
+	return sol
+}
+
+func (p *problem) result(itinerary Itinerary) ApiItinerary {
+	itinerary = itinerary.locks.withCompleted(itinerary)
+	itinerary.Accommodation = p.accommodation
+	return convertToApiItinerary(&itinerary)
+}
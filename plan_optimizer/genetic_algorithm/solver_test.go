@@ -0,0 +1,97 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// testSolvers creates every solver with a few settings which use randomness in different places.
+func testSolvers() map[string]func() Solver {
+	createGa := func(configure func(ga *GeneticAlgorithm)) func() Solver {
+		return func() Solver {
+			geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+			geneticAlgorithm.SetRunParameters(60, 30, 8)
+			configure(geneticAlgorithm)
+			return geneticAlgorithm
+		}
+	}
+	return map[string]func() Solver{
+		"ga": createGa(func(ga *GeneticAlgorithm) {}),
+		"ga with operators": createGa(func(ga *GeneticAlgorithm) {
+			ga.SetSelectionStrategy(StochasticUniversalSampling{})
+			ga.SetReplacementStrategy(CrowdingReplacement{})
+			ga.SetCrossoverOperators(OrderCrossover{}, PartiallyMappedCrossover{}, EdgeRecombinationCrossover{},
+				DayBoundaryCrossover{})
+			ga.SetSeeding(&Seeding{Fraction: 0.2, Candidates: 5})
+			ga.SetLocalSearch(&LocalSearch{TopK: 3, Interval: 10, MaxPasses: 1})
+			ga.SetRestart(&Restart{Threshold: 0.3})
+		}),
+		"ga with islands": createGa(func(ga *GeneticAlgorithm) {
+			ga.SetIslandModel(&IslandModel{Islands: 4, MigrationInterval: 5, Migrants: 2})
+		}),
+		"sa": func() Solver {
+			sa := CreateSimulatedAnnealing(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+			sa.SetIterations(2000)
+			return sa
+		},
+		"ils": func() Solver {
+			ils := CreateIteratedLocalSearch(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+			ils.SetIterations(10)
+			return ils
+		},
+		"aco": func() Solver {
+			aco := CreateAntColonyOptimization(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+			aco.SetColony(10, 10)
+			return aco
+		},
+	}
+}
+
+func TestSolveWithSeedIsRepeatable(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(5)), 25)
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	for name, create := range testSolvers() {
+		var first ApiItinerary
+		// the result must not depend on the number of goroutines running the solver either
+		for run, procs := range []int{1, 8, 8, 3} {
+			runtime.GOMAXPROCS(procs)
+			solver := create()
+			for _, poi := range pois {
+				solver.AddPoi(poi)
+			}
+			solver.SetSeed(42)
+			itinerary, report := solver.Solve(context.Background())
+			if report.Seed != 42 {
+				t.Errorf("%s: run reported seed %d", name, report.Seed)
+			}
+			if run == 0 {
+				first = itinerary
+			} else if !reflect.DeepEqual(itinerary, first) {
+				t.Errorf("%s: run %d with GOMAXPROCS %d returned another itinerary for the same seed", name, run, procs)
+			}
+		}
+	}
+}
+
+func TestSolversReportStopReason(t *testing.T) {
+	pois := randomPois(rand.New(rand.NewSource(5)), 15)
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	for name, create := range testSolvers() {
+		for _, test := range []struct {
+			ctx    context.Context
+			reason string
+		}{{context.Background(), StopIterations}, {cancelled, StopCancelled}} {
+			solver := create()
+			for _, poi := range pois {
+				solver.AddPoi(poi)
+			}
+			if _, report := solver.Solve(test.ctx); report.StopReason != test.reason {
+				t.Errorf("%s: run expected to stop with %q stopped with %q", name, test.reason, report.StopReason)
+			}
+		}
+	}
+}
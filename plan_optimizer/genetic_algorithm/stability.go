@@ -0,0 +1,91 @@
+package genetic_algorithm
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stability configures a penalty for differences from a Reference itinerary, e.g. the plan which is re-planned
+// after a small change of the request. Every visit of the reference which is dropped, moved to another day or
+// starts more than ShiftMinutes minutes earlier or later costs Weight, so that the objective function trades
+// satisfaction for a stable plan. ShiftMinutes is 30 by default. Visits of new POIs are not penalized.
+type Stability struct {
+	Reference    *ApiItinerary
+	Weight       float64
+	ShiftMinutes int
+}
+
+// referenceVisit is the day and the start of a visit of the reference itinerary.
+type referenceVisit struct {
+	dayId int
+	start time.Time
+}
+
+// stabilityReference is the converted Stability configuration used by the objective function.
+type stabilityReference struct {
+	visits map[*POI]referenceVisit
+	weight float64
+	shift  int
+}
+
+// SetStability enables the stability penalty in the objective function, nil disables it. It has to be called
+// after all POIs are added and fails if the reference visits a POI which was not added. Visits of POIs which
+// SetTripProgress does not plan again are ignored. The exact solver ignores the penalty.
+func (p *problem) SetStability(stability *Stability) error {
+	if stability == nil {
+		p.stability = nil
+		return nil
+	}
+	if stability.Reference == nil {
+		return fmt.Errorf("stability requires a reference itinerary")
+	}
+	if err := checkKnownPois(stability.Reference, p.tripPoiList()); err != nil {
+		return err
+	}
+	reference, err := convertFromApiItinerary(stability.Reference, p.poiList)
+	if err != nil {
+		return err
+	}
+	shift := stability.ShiftMinutes
+	if shift <= 0 {
+		shift = 30
+	}
+	p.stability = &stabilityReference{visits: make(map[*POI]referenceVisit), weight: stability.Weight, shift: shift}
+	for dayId, day := range reference.Days {
+		for _, visit := range day.Visits {
+			p.stability.visits[visit.Poi] = referenceVisit{dayId: dayId, start: visit.StartVisit}
+		}
+	}
+	return nil
+}
+
+// changedVisits counts the visits of the reference which the itinerary dropped, moved to another day or shifted.
+func (s *stabilityReference) changedVisits(itinerary *Itinerary) int {
+	found := 0
+	changed := 0
+	for dayId, day := range itinerary.Days {
+		for _, visit := range day.Visits {
+			reference, ok := s.visits[visit.Poi]
+			if !ok {
+				continue
+			}
+			found++
+			shift := calculateDuration(reference.start, visit.StartVisit)
+			if shift < 0 {
+				shift = -shift
+			}
+			if reference.dayId != dayId || shift > s.shift {
+				changed++
+			}
+		}
+	}
+	return changed + len(s.visits) - found
+}
+
+// stabilityPenalty is the penalty for differences of the itinerary from the reference, 0 without one.
+func (p *problem) stabilityPenalty(itinerary *Itinerary) float64 {
+	if p.stability == nil {
+		return 0.0
+	}
+	return p.stability.weight * float64(p.stability.changedVisits(itinerary))
+}
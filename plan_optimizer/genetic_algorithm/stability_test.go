@@ -0,0 +1,75 @@
+package genetic_algorithm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStabilityPenaltyCountsChangedVisits(t *testing.T) {
+	pois := breakTestPois(7)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	durations := map[*POI]int{pois[0]: 60, pois[1]: 60, pois[2]: 60, pois[3]: 60, pois[4]: 60, pois[5]: 60}
+	reference := decodeItinerary([][]*POI{pois[:3], pois[3:6]}, durations, clock(9*60), clock(19*60), testDays)
+	apiReference := convertToApiItinerary(&reference)
+	if err := geneticAlgorithm.SetStability(&Stability{Reference: &apiReference, Weight: 2.5,
+		ShiftMinutes: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	shifted := func(visit Visit, minutes int) Visit {
+		visit.StartVisit = addMinutes(visit.StartVisit, minutes)
+		visit.EndVisit = addMinutes(visit.EndVisit, minutes)
+		return visit
+	}
+	first, second := reference.Days[0].Visits, reference.Days[1].Visits
+	for _, test := range []struct {
+		name    string
+		days    [][]Visit
+		changed int
+	}{
+		{"unchanged", [][]Visit{first, second}, 0},
+		{"shifted within the limit", [][]Visit{{shifted(first[0], 20), first[1], first[2]}, second}, 0},
+		{"shifted", [][]Visit{{shifted(first[0], -21), first[1], shifted(first[2], 45)}, second}, 2},
+		{"dropped", [][]Visit{first[:2], second[1:]}, 2},
+		{"moved to another day", [][]Visit{{first[0], first[1]}, append([]Visit{first[2]}, second...)}, 1},
+		{"new POI added", [][]Visit{append(copyVisits(first), Visit{Poi: pois[6], StartVisit: clock(17 * 60),
+			EndVisit: clock(18 * 60)}), second}, 0},
+		{"empty", [][]Visit{nil, nil}, 6},
+	} {
+		itinerary := Itinerary{Days: []Day{{Visits: test.days[0]}, {Visits: test.days[1]}}}
+		penalty := geneticAlgorithm.stabilityPenalty(&itinerary)
+		if math.Abs(penalty-2.5*float64(test.changed)) > 1e-9 {
+			t.Errorf("%s: penalty %f, expected %d changed visits", test.name, penalty, test.changed)
+		}
+	}
+
+	if err := geneticAlgorithm.SetStability(nil); err != nil || geneticAlgorithm.stabilityPenalty(&reference) != 0 {
+		t.Errorf("disabled stability still penalizes itineraries")
+	}
+}
+
+func TestStabilityReferenceOfReplannedTrip(t *testing.T) {
+	geneticAlgorithm, plan := warmStartTestProblem(t)
+	apiItinerary := convertToApiItinerary(&plan)
+	// the first visit is over, its POI is not planned again
+	first := plan.Days[0].Visits[0]
+	progress := &TripProgress{Itinerary: &apiItinerary, Day: 0, Time: first.EndVisit, Position: first.Poi}
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+	if err := geneticAlgorithm.SetStability(&Stability{Reference: &apiItinerary, Weight: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := geneticAlgorithm.stability.visits[first.Poi]; ok || len(geneticAlgorithm.stability.visits) != 5 {
+		t.Errorf("stability reference keeps %d visits, expected all but the completed one",
+			len(geneticAlgorithm.stability.visits))
+	}
+
+	apiItinerary.Days[1].Visits[0].Poi.Name = "closed museum"
+	if err := geneticAlgorithm.SetStability(&Stability{Reference: &apiItinerary, Weight: 1}); err == nil {
+		t.Errorf("stability reference visiting an unknown POI is accepted")
+	}
+}
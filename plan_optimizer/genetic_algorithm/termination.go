@@ -0,0 +1,90 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"time"
+)
+
+// Reasons why a run of the algorithm stopped, reported in RunReport.
+const (
+	StopIterations        = "iterations"
+	StopCancelled         = "cancelled"
+	StopTimeBudget        = "time-budget"
+	StopStagnation        = "stagnation"
+	StopTargetObjective   = "target-objective"
+	StopDiversityCollapse = "diversity-collapse"
+	StopTooManyPois       = "too-many-pois"
+)
+
+// Termination configures optional conditions which stop Solve before all iterations are done. Zero values disable
+// the conditions: TimeBudget limits the wall-clock time of the run, StagnationGenerations stops it when the best
+// objective has not improved for that many generations, TargetObjective when the best objective reaches it and
+// MinimumDiversity when the diversity of the population drops below it.
+type Termination struct {
+	TimeBudget            time.Duration
+	StagnationGenerations int
+	TargetObjective       *float64
+	MinimumDiversity      float64
+}
+
+// SetTermination sets the conditions which stop Solve early, nil runs all iterations.
+func (ga *GeneticAlgorithm) SetTermination(termination *Termination) {
+	ga.termination = termination
+}
+
+// terminationCheck tracks the progress of a run to decide when it should stop.
+type terminationCheck struct {
+	termination     *Termination
+	parent          context.Context
+	bestObjective   float64
+	lastImprovement int
+}
+
+// startTermination returns the check of the termination conditions together with the context of the run, which
+// is done when the time budget runs out.
+func (ga *GeneticAlgorithm) startTermination(ctx context.Context) (*terminationCheck, context.Context,
+	context.CancelFunc) {
+	check := &terminationCheck{termination: ga.termination, parent: ctx, bestObjective: -1000000.0}
+	if ga.termination != nil && ga.termination.TimeBudget > 0 {
+		runCtx, cancel := context.WithTimeout(ctx, ga.termination.TimeBudget)
+		return check, runCtx, cancel
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	return check, runCtx, cancel
+}
+
+// iterationsStopReason is the reason why a solver which runs a fixed number of iterations stopped.
+func iterationsStopReason(ctx context.Context) string {
+	if ctx.Err() != nil {
+		return StopCancelled
+	}
+	return StopIterations
+}
+
+// stopReason returns why the run should stop before the given generation, or an empty string if it should go on.
+func (t *terminationCheck) stopReason(ctx context.Context, generation int, bestObjective float64,
+	population []solution) string {
+	if bestObjective > t.bestObjective {
+		t.bestObjective = bestObjective
+		t.lastImprovement = generation
+	}
+	if ctx.Err() != nil {
+		if t.parent.Err() != nil {
+			return StopCancelled
+		}
+		return StopTimeBudget
+	}
+	if t.termination == nil {
+		return ""
+	}
+	if t.termination.TargetObjective != nil && bestObjective >= *t.termination.TargetObjective {
+		return StopTargetObjective
+	}
+	if t.termination.StagnationGenerations > 0 && generation-t.lastImprovement >= t.termination.StagnationGenerations {
+		return StopStagnation
+	}
+	if t.termination.MinimumDiversity > 0 && populationDiversity(population) < t.termination.MinimumDiversity {
+		return StopDiversityCollapse
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package genetic_algorithm
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func terminationTestProblem(termination *Termination, iterations int) *GeneticAlgorithm {
+	pois := randomPois(rand.New(rand.NewSource(22)), 20)
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	geneticAlgorithm.SetRunParameters(20, iterations, 8)
+	geneticAlgorithm.SetSeed(1)
+	geneticAlgorithm.SetTermination(termination)
+	return geneticAlgorithm
+}
+
+func TestStopReasons(t *testing.T) {
+	target := -1000.0
+	for _, test := range []struct {
+		termination *Termination
+		iterations  int
+		reason      string
+	}{
+		{nil, 5, StopIterations},
+		{&Termination{StagnationGenerations: 3}, 1000000, StopStagnation},
+		{&Termination{TargetObjective: &target}, 1000000, StopTargetObjective},
+		{&Termination{TimeBudget: 50 * time.Millisecond}, 1000000, StopTimeBudget},
+		{&Termination{MinimumDiversity: 1.01}, 1000000, StopDiversityCollapse},
+	} {
+		_, report := terminationTestProblem(test.termination, test.iterations).Solve(context.Background())
+		if report.StopReason != test.reason {
+			t.Errorf("run expected to stop with %q stopped with %q", test.reason, report.StopReason)
+		}
+	}
+}
+
+func TestStagnationCountsGenerationsWithoutImprovement(t *testing.T) {
+	geneticAlgorithm := terminationTestProblem(&Termination{StagnationGenerations: 3}, 1000000)
+	_, report := geneticAlgorithm.Solve(context.Background())
+	generations := report.Generations
+	if report.StopReason != StopStagnation || len(generations) < 4 {
+		t.Fatalf("run stopped with %q after %d generations", report.StopReason, len(generations))
+	}
+	// the run stops three generations after the best objective last improved
+	last := generations[len(generations)-1].Best
+	for _, stats := range generations[len(generations)-4:] {
+		if stats.Best != last {
+			t.Errorf("best objective improved from %f to %f within the last generations", stats.Best, last)
+		}
+	}
+	if len(generations) > 4 && generations[len(generations)-5].Best >= last {
+		t.Errorf("best objective %f did not improve before stagnation", generations[len(generations)-5].Best)
+	}
+}
+
+func TestCancelledRunStops(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// a time budget longer than the deadline of the caller is not the reason
+	geneticAlgorithm := terminationTestProblem(&Termination{TimeBudget: time.Minute}, 1000000)
+	if _, report := geneticAlgorithm.Solve(ctx); report.StopReason != StopCancelled {
+		t.Errorf("cancelled run stopped with %q", report.StopReason)
+	}
+}
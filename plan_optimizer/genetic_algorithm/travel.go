@@ -0,0 +1,52 @@
+package genetic_algorithm
+
+import (
+	"math"
+)
+
+type Coordinate struct {
+	Lat float64
+	Lon float64
+}
+
+// Leg describes how to get from one POI to another.
+type Leg struct {
+	DistanceMeters  float64
+	DurationMinutes int
+	Mode            string
+	Geometry        []Coordinate
+}
+
+// TravelProvider computes legs between POIs. It is called concurrently from many goroutines during optimization,
+// so implementations have to be safe for concurrent use and should cache expensive results.
+type TravelProvider interface {
+	Travel(from *POI, to *POI) Leg
+}
+
+// HaversineTravel estimates legs from the great-circle distance, assuming 6 minutes per kilometer.
+type HaversineTravel struct{}
+
+func (HaversineTravel) Travel(from *POI, to *POI) Leg {
+	d := haversineDistance(from.Lat, from.Lon, to.Lat, to.Lon)
+	return Leg{
+		DistanceMeters:  d * 1000.0,
+		DurationMinutes: int(math.Ceil(d * 6.0)),
+		Mode:            "walking",
+		Geometry:        []Coordinate{{Lat: from.Lat, Lon: from.Lon}, {Lat: to.Lat, Lon: to.Lon}},
+	}
+}
+
+var travelProvider TravelProvider = HaversineTravel{}
+
+// SetTravelProvider replaces the provider used for all travel times. It should be called once, before any
+// optimization starts. Passing nil restores the haversine estimate.
+func SetTravelProvider(provider TravelProvider) {
+	if provider == nil {
+		provider = HaversineTravel{}
+	}
+	travelProvider = provider
+}
+
+func travel(from *POI, to *POI) Leg {
+	return travelProvider.Travel(from, to)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 )
 
@@ -13,25 +14,27 @@ func degrees2radians(degrees float64) float64 {
 	return degrees * math.Pi / 180
 }
 
-func transport(prevPoi *POI, newPoi *POI) int {
-	degreesLat := degrees2radians(newPoi.Lat - prevPoi.Lat)
-	degreesLong := degrees2radians(newPoi.Lon - prevPoi.Lon)
+func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
+	degreesLat := degrees2radians(lat2 - lat1)
+	degreesLong := degrees2radians(lon2 - lon1)
 	a := math.Sin(degreesLat/2)*math.Sin(degreesLat/2) +
-		math.Cos(degrees2radians(prevPoi.Lat))*
-			math.Cos(degrees2radians(newPoi.Lat))*math.Sin(degreesLong/2)*
+		math.Cos(degrees2radians(lat1))*
+			math.Cos(degrees2radians(lat2))*math.Sin(degreesLong/2)*
 			math.Sin(degreesLong/2)
 	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	d := radius * c
+	return radius * c
+}
 
-	return int(math.Ceil(d * 6.0))
+func transport(prevPoi *POI, newPoi *POI) int {
+	return travel(prevPoi, newPoi).DurationMinutes
 }
 
-func drawPoi(poiList []*POI) (*POI, int) {
+func drawPoi(poiList []*POI, random *rand.Rand) (*POI, int) {
 	// Randomly select a POI from the available list
 	if len(poiList) == 0 {
 		return nil, -1
 	}
-	index := rand.Intn(len(poiList))
+	index := random.Intn(len(poiList))
 	return poiList[index], index
 }
 
@@ -98,63 +101,6 @@ func openedDuringHours(poi *POI, startHour, endHour time.Time, day string) bool
 		(endTime.Before(closeTime) || endTime.Equal(closeTime))
 }
 
-func doesNewPoiFit(newPoi *POI, visits []Visit, visitId int, dayStartHour time.Time, dayEndHour time.Time, day string) (result bool,
-	visitStart time.Time, visitEnd time.Time) {
-	result = false
-	visitStart = time.Time{}
-	visitEnd = time.Time{}
-
-	if visitId == 0 {
-		// Substitute first point during the day
-		if len(visits) > 1 {
-			travelTime := transport(newPoi, visits[1].Poi)
-			startTime := maxHour(newPoi.OpenHour[day], dayStartHour)
-			endTime := minHour(newPoi.CloseHour[day], subtractMinutes(visits[1].StartVisit, travelTime))
-			if startTime.Before(endTime) && calculateDuration(startTime, endTime) >= 60 {
-				if openedDuringHours(newPoi, startTime, endTime, day) {
-					result = true
-					visitStart = startTime
-					visitEnd = endTime
-				}
-			}
-		} else {
-			if openedDuringHours(newPoi, visits[visitId].StartVisit, visits[visitId].EndVisit, day) {
-				result = true
-				visitStart = visits[visitId].StartVisit
-				visitEnd = visits[visitId].EndVisit
-			}
-		}
-	} else if visitId < len(visits)-1 {
-		// Substitute point in the middle of the day
-		travelFromPrev := transport(visits[visitId-1].Poi, newPoi)
-		travelToNext := transport(newPoi, visits[visitId+1].Poi)
-
-		startTime := maxHour(newPoi.OpenHour[day], addMinutes(visits[visitId-1].EndVisit, travelFromPrev))
-		endTime := minHour(newPoi.CloseHour[day], subtractMinutes(visits[visitId+1].StartVisit, travelToNext))
-
-		if startTime.Before(endTime) && calculateDuration(startTime, endTime) >= 60 {
-			if openedDuringHours(newPoi, startTime, endTime, day) {
-				result = true
-				visitStart = startTime
-				visitEnd = endTime
-			}
-		}
-	} else {
-		// Substitute point at the end of the day
-		travelTime := transport(visits[visitId-1].Poi, newPoi)
-		startTime := maxHour(newPoi.OpenHour[day], addMinutes(visits[visitId-1].EndVisit, travelTime))
-		endTime := minHour(newPoi.CloseHour[day], dayEndHour)
-		if startTime.Before(endTime) && calculateDuration(startTime, endTime) >= 60 {
-			if openedDuringHours(newPoi, startTime, endTime, day) {
-				result = true
-				visitStart = startTime
-				visitEnd = endTime
-			}
-		}
-	}
-	return result, visitStart, visitEnd
-}
-
 func minHour(times ...time.Time) time.Time {
 	if len(times) == 0 {
 		// No arguments provided, return a zero time
@@ -196,6 +142,7 @@ func convertToApiPOI(poi *POI) ApiPOI {
 		OpenHour:     convertTimeMapToString(poi.OpenHour),
 		CloseHour:    convertTimeMapToString(poi.CloseHour),
 		Satisfaction: poi.Satisfaction,
+		Categories:   poi.Categories,
 	}
 	return apiPOI
 }
@@ -214,24 +161,195 @@ func convertToApiItinerary(itinerary *Itinerary) ApiItinerary {
 		DayEndHour:   itinerary.DayEndHour.Format("15:04"),
 	}
 
-	for _, day := range itinerary.Days {
+	if itinerary.Accommodation != nil {
+		accommodation := convertToApiPOI(itinerary.Accommodation)
+		apiItinerary.Accommodation = &accommodation
+	}
+
+	for dayId, day := range itinerary.Days {
 		apiDay := ApiDay{
+			Legs:      createDayLegs(&day, itinerary.Accommodation),
 			DayNumber: day.DayNumber,
 			DayName:   day.DayName,
+			Locked:    itinerary.locks.dayLocked(dayId),
 		}
 
-		for _, visit := range day.Visits {
+		idleBreaks, mealBreaks := apiBreaks(&day, itinerary.DayBeginHour, itinerary.DayEndHour)
+		for i, visit := range day.Visits {
+			apiDay.Visits = append(apiDay.Visits, idleBreaks[i]...)
 			apiVisit := ApiVisit{
 				Poi:           convertToApiPOI(visit.Poi),
 				StartVisit:    visit.StartVisit.Format("15:04"),
 				EndVisit:      visit.EndVisit.Format("15:04"),
 				VisitDuration: visit.VisitDuration,
+				Locked:        !apiDay.Locked && itinerary.locks.visitLocked(visit.Poi),
+				Type:          visitType,
+				Break:         mealBreaks[i],
 			}
 			apiDay.Visits = append(apiDay.Visits, apiVisit)
 		}
+		apiDay.Visits = append(apiDay.Visits, idleBreaks[len(day.Visits)]...)
 
 		apiItinerary.Days = append(apiItinerary.Days, apiDay)
 	}
 
 	return apiItinerary
 }
+
+// poiKey identifies a POI of a request by its name and position.
+type poiKey struct {
+	name string
+	lat  float64
+	lon  float64
+}
+
+func apiPoiKey(poi ApiPOI) poiKey {
+	return poiKey{name: poi.Name, lat: poi.Lat, lon: poi.Lon}
+}
+
+func poisByKey(pois []*POI) map[poiKey]*POI {
+	byKey := make(map[poiKey]*POI, len(pois))
+	for _, poi := range pois {
+		byKey[poiKey{name: poi.Name, lat: poi.Lat, lon: poi.Lon}] = poi
+	}
+	return byKey
+}
+
+// checkKnownPois fails if a visit of the itinerary which is not a break is a visit of a POI missing from pois.
+func checkKnownPois(apiItinerary *ApiItinerary, pois []*POI) error {
+	byKey := poisByKey(pois)
+	for _, apiDay := range apiItinerary.Days {
+		for _, apiVisit := range apiDay.Visits {
+			if _, ok := byKey[apiPoiKey(apiVisit.Poi)]; !ok && apiVisit.Type != breakType {
+				return fmt.Errorf("visit of unknown POI %q", apiVisit.Poi.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// convertFromApiItinerary is the inverse of convertToApiItinerary. POIs of visits are matched by name and position
+// with pois and breaks and visits of unknown POIs are skipped, see checkKnownPois. Legs are not read. Hours after midnight which precede the beginning of the day
+// are moved to the next day, like in requests.
+func convertFromApiItinerary(apiItinerary *ApiItinerary, pois []*POI) (Itinerary, error) {
+	layout := "15:04"
+	byKey := poisByKey(pois)
+	dayBeginHour, err := time.Parse(layout, apiItinerary.DayBeginHour)
+	if err != nil {
+		return Itinerary{}, fmt.Errorf("invalid DayBeginHour %q", apiItinerary.DayBeginHour)
+	}
+	parseHour := func(value string) (time.Time, error) {
+		hour, err := time.Parse(layout, value)
+		if err != nil {
+			return hour, fmt.Errorf("invalid hour %q", value)
+		}
+		if hour.Before(dayBeginHour) {
+			hour = hour.Add(24 * time.Hour)
+		}
+		return hour, nil
+	}
+	dayEndHour, err := parseHour(apiItinerary.DayEndHour)
+	if err != nil {
+		return Itinerary{}, err
+	}
+
+	itinerary := Itinerary{DayBeginHour: dayBeginHour, DayEndHour: dayEndHour}
+	if apiItinerary.Accommodation != nil {
+		itinerary.Accommodation = &POI{
+			Name: apiItinerary.Accommodation.Name,
+			Lat:  apiItinerary.Accommodation.Lat,
+			Lon:  apiItinerary.Accommodation.Lon,
+		}
+	}
+	for _, apiDay := range apiItinerary.Days {
+		day := Day{Visits: make([]Visit, 0, len(apiDay.Visits)), DayNumber: apiDay.DayNumber, DayName: apiDay.DayName}
+		for _, apiVisit := range apiDay.Visits {
+			poi, ok := byKey[apiPoiKey(apiVisit.Poi)]
+			if !ok || apiVisit.Type == breakType {
+				continue
+			}
+			startVisit, err := parseHour(apiVisit.StartVisit)
+			if err != nil {
+				return Itinerary{}, err
+			}
+			endVisit, err := parseHour(apiVisit.EndVisit)
+			if err != nil {
+				return Itinerary{}, err
+			}
+			day.Visits = append(day.Visits, Visit{
+				Poi:           poi,
+				StartVisit:    startVisit,
+				EndVisit:      endVisit,
+				VisitDuration: apiVisit.VisitDuration,
+			})
+		}
+		itinerary.Days = append(itinerary.Days, day)
+	}
+	return itinerary, nil
+}
+
+func convertToApiLeg(from, to *POI, departure, arrival time.Time, leg Leg) ApiLeg {
+	return ApiLeg{
+		From:            from.Name,
+		To:              to.Name,
+		DistanceMeters:  math.Round(leg.DistanceMeters),
+		DurationMinutes: leg.DurationMinutes,
+		Departure:       departure.Format("15:04"),
+		Arrival:         arrival.Format("15:04"),
+		Mode:            leg.Mode,
+		Polyline:        encodePolyline(leg.Geometry),
+	}
+}
+
+// createDayLegs describes travel between consecutive visits. Departure is the end of the previous visit, so any
+// waiting for the next POI to open happens after arrival. If accommodation is known, legs to the first and from
+// the last visit of the day are added as well.
+func createDayLegs(day *Day, accommodation *POI) []ApiLeg {
+	legs := make([]ApiLeg, 0)
+	if len(day.Visits) == 0 {
+		return legs
+	}
+	if accommodation != nil {
+		first := day.Visits[0]
+		leg := travel(accommodation, first.Poi)
+		legs = append(legs, convertToApiLeg(accommodation, first.Poi,
+			subtractMinutes(first.StartVisit, leg.DurationMinutes), first.StartVisit, leg))
+	}
+	for i := 1; i < len(day.Visits); i++ {
+		prev := day.Visits[i-1]
+		next := day.Visits[i]
+		leg := travel(prev.Poi, next.Poi)
+		legs = append(legs, convertToApiLeg(prev.Poi, next.Poi, prev.EndVisit,
+			addMinutes(prev.EndVisit, leg.DurationMinutes), leg))
+	}
+	if accommodation != nil {
+		last := day.Visits[len(day.Visits)-1]
+		leg := travel(last.Poi, accommodation)
+		legs = append(legs, convertToApiLeg(last.Poi, accommodation, last.EndVisit,
+			addMinutes(last.EndVisit, leg.DurationMinutes), leg))
+	}
+	return legs
+}
+
+// parallelFor calls task for every index from 0 to count-1 on at most workers goroutines and waits for all of them.
+func parallelFor(count int, workers int, task func(i int)) {
+	if workers > count {
+		workers = count
+	}
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				task(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
@@ -0,0 +1,59 @@
+package genetic_algorithm
+
+import "math/rand"
+
+// warmStart is a repaired itinerary of an earlier run which seeds the initial population.
+type warmStart struct {
+	itinerary Itinerary
+	variants  int
+}
+
+// SetWarmStart makes the next runs start from an itinerary returned earlier, e.g. for a similar request, so that
+// re-planning is fast and the new plan stays close to the old one. It has to be called after all POIs are added and
+// fails if the itinerary visits a POI which was not added. Visits of POIs which SetTripProgress does not plan again
+// are dropped, days are matched by their order and the visits are
+// scheduled again with decodeItinerary for the current days and hours, keeping their durations where possible.
+// The repaired itinerary and variants copies changed by random mutations are put into the initial population.
+// nil disables the warm start.
+func (ga *GeneticAlgorithm) SetWarmStart(itinerary *ApiItinerary, variants int) error {
+	if itinerary == nil {
+		ga.warmStart = nil
+		return nil
+	}
+	if err := checkKnownPois(itinerary, ga.tripPoiList()); err != nil {
+		return err
+	}
+	converted, err := convertFromApiItinerary(itinerary, ga.poiList)
+	if err != nil {
+		return err
+	}
+	order := make([][]*POI, len(ga.daysList))
+	durations := make(map[*POI]int)
+	for dayId, day := range converted.Days {
+		if dayId >= len(ga.daysList) {
+			break
+		}
+		for _, visit := range day.Visits {
+			if _, ok := durations[visit.Poi]; ok {
+				continue
+			}
+			order[dayId] = append(order[dayId], visit.Poi)
+			durations[visit.Poi] = visit.VisitDuration
+		}
+	}
+	ga.warmStart = &warmStart{
+		itinerary: decodeItinerary(order, durations, ga.dayBeginHour, ga.dayEndHour, ga.daysList),
+		variants:  variants,
+	}
+	return nil
+}
+
+// variant returns a copy of the warm start itinerary changed by one to three random mutations.
+func (w *warmStart) variant(poiList []*POI, random *rand.Rand) Itinerary {
+	operators := AllMutationOperators()
+	sol := solution{itinerary: copyItinerary(&w.itinerary)}
+	for i := random.Intn(3); i >= 0; i-- {
+		operators[random.Intn(len(operators))].mutate(&sol, poiList, random)
+	}
+	return sol.itinerary
+}
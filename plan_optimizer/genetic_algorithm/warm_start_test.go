@@ -0,0 +1,105 @@
+package genetic_algorithm
+
+import "testing"
+
+// warmStartTestProblem returns a genetic algorithm with a lunch break and a plan of its POIs, the second one a
+// restaurant.
+func warmStartTestProblem(t *testing.T) (*GeneticAlgorithm, Itinerary) {
+	pois := breakTestPois(8)
+	pois[1].Categories = []string{"catering.restaurant"}
+	geneticAlgorithm := CreateGeneticAlgorithm(clock(9*60), clock(19*60), testDays, 0.05, 1000.0, 1.0)
+	for _, poi := range pois {
+		geneticAlgorithm.AddPoi(poi)
+	}
+	if err := geneticAlgorithm.SetBreaks([]Break{lunch}); err != nil {
+		t.Fatal(err)
+	}
+	durations := map[*POI]int{pois[0]: 150, pois[1]: 75, pois[3]: 200, pois[4]: 90}
+	plan := decodeItinerary([][]*POI{pois[:3], pois[3:6]}, durations, clock(9*60), clock(19*60), testDays)
+	geneticAlgorithm.scheduleBreaks(&plan)
+	plan.Accommodation = &POI{Name: "hotel", Lat: 50.06, Lon: 19.94}
+	return geneticAlgorithm, plan
+}
+
+func checkSameItinerary(t *testing.T, name string, itinerary, expected *Itinerary) {
+	t.Helper()
+	if !itinerary.DayBeginHour.Equal(expected.DayBeginHour) || !itinerary.DayEndHour.Equal(expected.DayEndHour) ||
+		len(itinerary.Days) != len(expected.Days) {
+		t.Fatalf("%s has %d days from %s to %s", name, len(itinerary.Days), itinerary.DayBeginHour.Format("15:04"),
+			itinerary.DayEndHour.Format("15:04"))
+	}
+	for dayId, day := range itinerary.Days {
+		if day.DayNumber != expected.Days[dayId].DayNumber || day.DayName != expected.Days[dayId].DayName ||
+			!sameVisits(day.Visits, expected.Days[dayId].Visits) {
+			t.Errorf("%s changed day %d to %+v", name, dayId, day)
+		}
+		for visitId, visit := range day.Visits {
+			if visitId < len(expected.Days[dayId].Visits) &&
+				visit.VisitDuration != expected.Days[dayId].Visits[visitId].VisitDuration {
+				t.Errorf("%s changed the duration of %s to %d", name, visit.Poi.Name, visit.VisitDuration)
+			}
+		}
+	}
+}
+
+func TestApiItineraryRoundTrip(t *testing.T) {
+	geneticAlgorithm, plan := warmStartTestProblem(t)
+	apiItinerary := convertToApiItinerary(&plan)
+	breaks := 0
+	for _, apiDay := range apiItinerary.Days {
+		for _, apiVisit := range apiDay.Visits {
+			if apiVisit.Type == breakType {
+				breaks++
+			}
+		}
+	}
+	if breaks == 0 {
+		t.Fatalf("the returned itinerary has no break visits")
+	}
+
+	converted, err := convertFromApiItinerary(&apiItinerary, geneticAlgorithm.poiList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSameItinerary(t, "conversion", &converted, &plan)
+	if accommodation := converted.Accommodation; accommodation == nil || accommodation.Name != "hotel" ||
+		accommodation.Lat != plan.Accommodation.Lat || accommodation.Lon != plan.Accommodation.Lon {
+		t.Errorf("accommodation converted to %+v", accommodation)
+	}
+
+	// the warm start schedules the visits again with the same result, once breaks are taken like in the population
+	if err = geneticAlgorithm.SetWarmStart(&apiItinerary, 0); err != nil {
+		t.Fatal(err)
+	}
+	warmStart := copyItinerary(&geneticAlgorithm.warmStart.itinerary)
+	geneticAlgorithm.scheduleBreaks(&warmStart)
+	checkSameItinerary(t, "warm start", &warmStart, &plan)
+}
+
+func TestWarmStartRejectsUnknownPois(t *testing.T) {
+	geneticAlgorithm, plan := warmStartTestProblem(t)
+	apiItinerary := convertToApiItinerary(&plan)
+	apiItinerary.Days[1].Visits[0].Poi.Name = "closed museum"
+
+	if err := geneticAlgorithm.SetWarmStart(&apiItinerary, 0); err == nil {
+		t.Errorf("warm start visiting an unknown POI is accepted")
+	}
+}
+
+func TestWarmStartDropsPoisOfCompletedVisits(t *testing.T) {
+	geneticAlgorithm, plan := warmStartTestProblem(t)
+	apiItinerary := convertToApiItinerary(&plan)
+	// the first visit is over, its POI is not planned again
+	first := plan.Days[0].Visits[0]
+	progress := &TripProgress{Itinerary: &apiItinerary, Day: 0, Time: first.EndVisit, Position: first.Poi}
+	if err := geneticAlgorithm.SetTripProgress(progress); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := geneticAlgorithm.SetWarmStart(&apiItinerary, 0); err != nil {
+		t.Fatal(err)
+	}
+	if dayContainsPoi(geneticAlgorithm.warmStart.itinerary.Days[0], first.Poi) {
+		t.Errorf("warm start visits the POI of the completed visit again")
+	}
+}
@@ -5,6 +5,7 @@ go 1.20
 require (
 	genetic_algorithm v0.0.0-00010101000000-000000000000
 	github.com/gin-gonic/gin v1.9.1
+	routing v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -35,3 +36,5 @@ require (
 )
 
 replace genetic_algorithm => ./genetic_algorithm
+
+replace routing => ./routing
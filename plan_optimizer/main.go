@@ -1,24 +1,219 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	ga "genetic_algorithm"
 	"net/http"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 )
 
 type incomingData struct {
-	PoiList  []ga.ApiPOI `json:"poiList"`
-	Days     []string    `json:"days"`
-	DayStart string      `json:"dayStart"`
-	DayEnd   string      `json:"dayEnd"`
+	PoiList       []ga.ApiPOI `json:"poiList"`
+	Days          []string    `json:"days"`
+	DayStart      string      `json:"dayStart"`
+	DayEnd        string      `json:"dayEnd"`
+	Accommodation *ga.ApiPOI  `json:"accommodation"`
+	StartDate     string      `json:"startDate"` // 2006-01-02, date of the first day, needed by calendar exports
+	TimeZone      string      `json:"timeZone"`
+
+	Solver      string              `json:"solver"` // ga, ils, sa, aco, exact
+	Selection   *selectionOptions   `json:"selection"`
+	Replacement *replacementOptions `json:"replacement"`
+	Crossover   []string            `json:"crossover"` // day, ox, pmx, erx
+	Mutation    *mutationOptions    `json:"mutation"`
+	LocalSearch *localSearchOptions `json:"localSearch"`
+	Islands     *islandOptions      `json:"islands"`
+	Termination *terminationOptions `json:"termination"`
+	Restart     *restartOptions     `json:"restart"`
+	Seeding     *seedingOptions     `json:"seeding"`
+	Pareto      bool                `json:"pareto"` // return the Pareto front of the genetic algorithm
+
+	SolverOptions *solverOptions `json:"solverOptions"` // settings of the ils, sa and aco solvers
+
+	Alternatives        int      `json:"alternatives"`        // number of distinct itineraries to return
+	AlternativeDistance *float64 `json:"alternativeDistance"` // minimum Jaccard distance between them, 0.3 by default
+	AlternativeOrdering bool     `json:"alternativeOrdering"` // compare the order of visits instead of POI sets
+
+	WarmStart         *ga.ApiItinerary `json:"warmStart"`         // itinerary of an earlier request to start from
+	WarmStartVariants *int             `json:"warmStartVariants"` // mutated copies of it, a tenth of the population by default
+
+	Itinerary *ga.ApiItinerary  `json:"itinerary"` // itinerary re-planned by /reoptimize with its locked days and visits
+	Stability *stabilityOptions `json:"stability"`
+	Progress  *progressOptions  `json:"progress"` // state of the trip re-planned by /replan
+
+	Breaks []breakOptions `json:"breaks"` // breaks taken every day, like lunch
+
+	Seed   int64 `json:"seed"`   // seed of the random number generator, a new one for every run by default
+	Report bool  `json:"report"` // include the run report in a JSON response
+}
+
+type bestRouteResponse struct {
+	ga.ApiItinerary
+	Alternatives []ga.Alternative `json:"alternatives,omitempty"`
+	StopReason   string           `json:"stopReason,omitempty"` // why the solver stopped
+	Report       *ga.RunReport    `json:"report,omitempty"`
+}
+
+type paretoResponse struct {
+	Front  []ga.ParetoSolution `json:"front"`
+	Report *ga.RunReport       `json:"report,omitempty"`
+}
+
+const populationSize = 300
+const iterations = 100
+const solutionTTL = 8
+
+// weights of the objective function
+const poiMultiplier = 0.05
+const penaltyMultiplier = 1000.0
+const satisfactionMultiplier = 1
+
+var outputFormats = map[string]bool{"json": true, "geojson": true, "ics": true, "gpx": true, "kml": true}
+
+const defaultTimeZone = "Europe/Warsaw"
+
+type exportOptions struct {
+	startDate time.Time
+	location  *time.Location
+}
+
+func parseExportOptions(ind *incomingData, format string) (exportOptions, error) {
+	options := exportOptions{location: time.UTC}
+	timeZone := ind.TimeZone
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return options, fmt.Errorf("unknown time zone %q", timeZone)
+	}
+	options.location = location
+
+	if ind.StartDate == "" {
+		if format == "ics" {
+			return options, fmt.Errorf("startDate is required for the %s format", format)
+		}
+		return options, nil
+	}
+	options.startDate, err = time.ParseInLocation("2006-01-02", ind.StartDate, location)
+	if err != nil {
+		return options, fmt.Errorf("invalid startDate %q, expected YYYY-MM-DD", ind.StartDate)
+	}
+	return options, nil
 }
 
 func getBestRoute(context *gin.Context) {
+	sendBestRoute(context, context.DefaultQuery("format", "json"))
+}
+
+func exportBestRoute(format string) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		sendBestRoute(context, format)
+	}
+}
+
+func writeItinerary(context *gin.Context, response bestRouteResponse, format string, options exportOptions) {
+	itinerary := response.ApiItinerary
+	switch format {
+	case "geojson":
+		body, err := json.MarshalIndent(ga.ConvertToGeoJSON(itinerary), "", "    ")
+		if err != nil {
+			context.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		context.Data(http.StatusOK, "application/geo+json", body)
+	case "ics":
+		context.Header("Content-Disposition", `attachment; filename="itinerary.ics"`)
+		context.Data(http.StatusOK, "text/calendar; charset=utf-8",
+			[]byte(ga.ConvertToICalendar(itinerary, options.startDate, options.location)))
+	case "gpx", "kml":
+		convert, contentType := ga.ConvertToGPX, "application/gpx+xml"
+		if format == "kml" {
+			convert, contentType = ga.ConvertToKML, "application/vnd.google-earth.kml+xml"
+		}
+		body, err := convert(itinerary)
+		if err != nil {
+			context.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		context.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="itinerary.%s"`, format))
+		context.Data(http.StatusOK, contentType, body)
+	default:
+		context.IndentedJSON(http.StatusOK, response)
+	}
+}
+
+// bindRequest reads the request of an endpoint. The itinerary is required by /reoptimize and /replan, which also
+// start from it, and the progress of the trip by /replan. Other endpoints reject them.
+func bindRequest(context *gin.Context, format string, endpoint string) (*incomingData, bool) {
+	if !outputFormats[format] {
+		context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+		return nil, false
+	}
 	var ind incomingData
 	if err := context.BindJSON(&ind); err != nil {
+		return nil, false
+	}
+	switch {
+	case ind.Itinerary == nil && endpoint != "best-route":
+		context.JSON(http.StatusBadRequest, gin.H{"error": "itinerary is required"})
+		return nil, false
+	case ind.Itinerary != nil && endpoint == "best-route":
+		context.JSON(http.StatusBadRequest, gin.H{"error": "itinerary is only accepted by /reoptimize and /replan"})
+		return nil, false
+	case ind.Progress == nil && endpoint == "replan":
+		context.JSON(http.StatusBadRequest, gin.H{"error": "progress is required"})
+		return nil, false
+	case ind.Progress != nil && endpoint != "replan":
+		context.JSON(http.StatusBadRequest, gin.H{"error": "progress is only accepted by /replan"})
+		return nil, false
+	}
+	if ind.Itinerary != nil && ind.WarmStart == nil {
+		ind.WarmStart = ind.Itinerary
+	}
+	return &ind, true
+}
+
+// reoptimizeRoute re-plans the itinerary of the request with the genetic algorithm, which keeps its days and visits
+// marked as locked and starts from the rest of it.
+func reoptimizeRoute(context *gin.Context) {
+	format := context.DefaultQuery("format", "json")
+	if ind, ok := bindRequest(context, format, "reoptimize"); ok {
+		solveRequest(context, ind, format)
+	}
+}
+
+// replanRoute re-plans the rest of a trip in progress from the current position and time, keeping the visits
+// which are already completed.
+func replanRoute(context *gin.Context) {
+	format := context.DefaultQuery("format", "json")
+	if ind, ok := bindRequest(context, format, "replan"); ok {
+		solveRequest(context, ind, format)
+	}
+}
+
+func sendBestRoute(context *gin.Context, format string) {
+	if ind, ok := bindRequest(context, format, "best-route"); ok {
+		solveRequest(context, ind, format)
+	}
+}
+
+func solveRequest(context *gin.Context, ind *incomingData, format string) {
+	options, err := parseExportOptions(ind, format)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if ind.Pareto && format != "json" {
+		context.JSON(http.StatusBadRequest, gin.H{"error": "the Pareto front is only available in the json format"})
+		return
+	}
+	if ind.Alternatives != 0 && format != "json" {
+		context.JSON(http.StatusBadRequest, gin.H{"error": "alternatives are only available in the json format"})
 		return
 	}
 	layout := "15:04"
@@ -28,7 +223,11 @@ func getBestRoute(context *gin.Context) {
 		dayEnd = dayEnd.Add(24 * time.Hour)
 	}
 	dayCode := []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
-	geneticAlgorithm := ga.CreateGeneticAlgorithm(dayStart, dayEnd, ind.Days, 0.05, 1000.0, 1)
+	solver, err := createSolver(ind, dayStart, dayEnd)
+	if err != nil {
+		context.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	var closingHours map[string]time.Time
 	var openingHours map[string]time.Time
 
@@ -45,21 +244,96 @@ func getBestRoute(context *gin.Context) {
 				closingHours[day] = closingHours[day].Add(24 * time.Hour)
 			}
 		}
-		geneticAlgorithm.AddPoi(&ga.POI{
+		solver.AddPoi(&ga.POI{
 			Name:         p.Name,
 			CloseHour:    closingHours,
 			OpenHour:     openingHours,
 			Lat:          p.Lat,
 			Lon:          p.Lon,
 			Satisfaction: p.Satisfaction,
+			Categories:   p.Categories,
+		})
+	}
+	solver.SetSeed(ind.Seed)
+	if ind.Accommodation != nil {
+		solver.SetAccommodation(&ga.POI{
+			Name: ind.Accommodation.Name,
+			Lat:  ind.Accommodation.Lat,
+			Lon:  ind.Accommodation.Lon,
 		})
 	}
-	bestItinerary := geneticAlgorithm.Run(300, 100, 8)
-	context.IndentedJSON(http.StatusOK, bestItinerary)
+	// the progress of the trip removes POIs which are not planned again, so it is set before the warm start
+	if ind.Progress != nil {
+		progress, err := parseProgress(ind, dayStart)
+		if err == nil {
+			err = solver.(*ga.GeneticAlgorithm).SetTripProgress(progress)
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid progress: %s", err)})
+			return
+		}
+	}
+	if ind.WarmStart != nil {
+		if err := solver.(*ga.GeneticAlgorithm).SetWarmStart(ind.WarmStart, warmStartVariants(ind)); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid warmStart: %s", err)})
+			return
+		}
+	}
+	if ind.Itinerary != nil && ind.Progress == nil {
+		if err := solver.(*ga.GeneticAlgorithm).SetLocks(ind.Itinerary); err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid itinerary: %s", err)})
+			return
+		}
+	}
+	if ind.Stability != nil {
+		stability, err := parseStability(ind)
+		if err == nil {
+			err = solver.(*ga.GeneticAlgorithm).SetStability(stability)
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid stability: %s", err)})
+			return
+		}
+	}
+	if len(ind.Breaks) > 0 {
+		breaks, err := parseBreaks(ind, dayStart)
+		if err == nil {
+			err = solver.(*ga.GeneticAlgorithm).SetBreaks(breaks)
+		}
+		if err != nil {
+			context.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid breaks: %s", err)})
+			return
+		}
+	}
+	if ind.Pareto {
+		front, report := solver.(*ga.GeneticAlgorithm).SolvePareto(context.Request.Context())
+		response := paretoResponse{Front: front}
+		if ind.Report {
+			response.Report = &report
+		}
+		context.IndentedJSON(http.StatusOK, response)
+		return
+	}
+	bestItinerary, report := solver.Solve(context.Request.Context())
+	response := bestRouteResponse{ApiItinerary: bestItinerary, StopReason: report.StopReason}
+	if geneticAlgorithm, ok := solver.(*ga.GeneticAlgorithm); ok {
+		response.Alternatives = geneticAlgorithm.Alternatives()
+	}
+	if ind.Report {
+		response.Report = &report
+	}
+	writeItinerary(context, response, format, options)
 }
 
 func main() {
+	configureTravel()
 	router := gin.Default()
 	router.POST("/best-route", getBestRoute)
+	router.POST("/best-route.geojson", exportBestRoute("geojson"))
+	router.POST("/best-route.ics", exportBestRoute("ics"))
+	router.POST("/best-route.gpx", exportBestRoute("gpx"))
+	router.POST("/best-route.kml", exportBestRoute("kml"))
+	router.POST("/reoptimize", reoptimizeRoute)
+	router.POST("/replan", replanRoute)
 	router.Run("0.0.0.0:6000")
 }
@@ -0,0 +1,721 @@
+package main
+
+import (
+	"fmt"
+	ga "genetic_algorithm"
+	"time"
+)
+
+type selectionOptions struct {
+	Type           string  `json:"type"` // roulette, tournament, rank, sus, sharing
+	TournamentSize int     `json:"tournamentSize"`
+	Pressure       float64 `json:"pressure"`
+	SharingRadius  float64 `json:"sharingRadius"`
+}
+
+func parseSelection(options *selectionOptions) (ga.SelectionStrategy, error) {
+	if options == nil {
+		return ga.RouletteWheelSelection{}, nil
+	}
+	switch options.Type {
+	case "", "roulette":
+		return ga.RouletteWheelSelection{}, nil
+	case "tournament":
+		return ga.TournamentSelection{Size: options.TournamentSize}, nil
+	case "rank":
+		return ga.LinearRankSelection{Pressure: options.Pressure}, nil
+	case "sus":
+		return ga.StochasticUniversalSampling{}, nil
+	case "sharing":
+		return ga.FitnessSharingSelection{Radius: options.SharingRadius}, nil
+	}
+	return nil, fmt.Errorf("unknown selection type %q", options.Type)
+}
+
+type replacementOptions struct {
+	Type           string  `json:"type"`    // plus, comma, steady-state, age, crowding
+	Elitism        int     `json:"elitism"` // for steady-state and age, at least 1
+	OffspringRatio float64 `json:"offspringRatio"`
+	Offspring      int     `json:"offspring"`
+	TTL            int     `json:"ttl"`
+	WindowSize     int     `json:"windowSize"`
+}
+
+// parseReplacement returns nil when the request does not choose a strategy, so that the default of Run is used.
+func parseReplacement(options *replacementOptions) (ga.ReplacementStrategy, error) {
+	if options == nil || options.Type == "" {
+		return nil, nil
+	}
+	if options.Elitism < 0 {
+		return nil, fmt.Errorf("elitism must not be negative, got %d", options.Elitism)
+	}
+	// the best solution always survives, otherwise the age limit can remove the whole population
+	elitism := options.Elitism
+	if elitism < 1 {
+		elitism = 1
+	}
+	switch options.Type {
+	case "plus":
+		return ga.PlusReplacement{}, nil
+	case "comma":
+		return ga.CommaReplacement{OffspringRatio: options.OffspringRatio}, nil
+	case "steady-state":
+		return ga.SteadyStateReplacement{Elitism: elitism, Offspring: options.Offspring}, nil
+	case "age":
+		ttl := options.TTL
+		if ttl <= 0 {
+			ttl = solutionTTL
+		}
+		return ga.AgeBasedReplacement{TTL: ttl, Elitism: elitism}, nil
+	case "crowding":
+		return ga.CrowdingReplacement{WindowSize: options.WindowSize}, nil
+	}
+	return nil, fmt.Errorf("unknown replacement type %q", options.Type)
+}
+
+var crossoverOperators = map[string]ga.CrossoverOperator{
+	"day": ga.DayBoundaryCrossover{},
+	"ox":  ga.OrderCrossover{},
+	"pmx": ga.PartiallyMappedCrossover{},
+	"erx": ga.EdgeRecombinationCrossover{},
+}
+
+func parseCrossover(names []string) ([]ga.CrossoverOperator, error) {
+	operators := make([]ga.CrossoverOperator, 0, len(names))
+	for _, name := range names {
+		operator, ok := crossoverOperators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown crossover operator %q", name)
+		}
+		operators = append(operators, operator)
+	}
+	return operators, nil
+}
+
+type mutationOptions struct {
+	Type          string             `json:"type"` // adaptive, fixed
+	Rate          float64            `json:"rate"` // probability of mutating an offspring, for adaptive selection
+	Operators     []string           `json:"operators"`
+	Probabilities map[string]float64 `json:"probabilities"` // probability of each operator, for fixed rates
+}
+
+var mutationOperators = map[string]ga.MutationOperator{
+	"substitute": ga.SubstituteMutation{DayProbability: ga.DAY_MUTATION_PROBABILITY},
+	"insert":     ga.InsertMutation{},
+	"remove":     ga.RemoveMutation{},
+	"swap":       ga.SwapMutation{},
+	"move":       ga.MoveMutation{},
+	"reverse":    ga.ReverseMutation{},
+	"extend":     ga.ExtendMutation{},
+}
+
+// parseMutation returns nil when the request does not choose a selection, so that the default of Run is used.
+func parseMutation(options *mutationOptions) (ga.MutationSelection, error) {
+	if options == nil || options.Type == "" {
+		return nil, nil
+	}
+	switch options.Type {
+	case "adaptive":
+		operators := ga.AllMutationOperators()
+		if len(options.Operators) > 0 {
+			operators = make([]ga.MutationOperator, 0, len(options.Operators))
+			for _, name := range options.Operators {
+				operator, ok := mutationOperators[name]
+				if !ok {
+					return nil, fmt.Errorf("unknown mutation operator %q", name)
+				}
+				operators = append(operators, operator)
+			}
+		}
+		rate := options.Rate
+		if rate <= 0 {
+			rate = ga.MUTATION_PROBABILITY
+		}
+		return ga.CreateAdaptivePursuit(operators, rate), nil
+	case "fixed":
+		rates := make([]ga.MutationRate, 0, len(options.Probabilities))
+		for name, probability := range options.Probabilities {
+			operator, ok := mutationOperators[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown mutation operator %q", name)
+			}
+			rates = append(rates, ga.MutationRate{Operator: operator, Probability: probability})
+		}
+		return ga.FixedMutationRates{Rates: rates}, nil
+	}
+	return nil, fmt.Errorf("unknown mutation type %q", options.Type)
+}
+
+type localSearchOptions struct {
+	TopK      int `json:"topK"`
+	Interval  int `json:"interval"` // number of iterations between local search phases
+	MaxPasses int `json:"maxPasses"`
+}
+
+// parseLocalSearch returns nil when local search is not requested.
+func parseLocalSearch(options *localSearchOptions) (*ga.LocalSearch, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.TopK < 0 || options.Interval < 0 || options.MaxPasses < 0 {
+		return nil, fmt.Errorf("local search options cannot be negative")
+	}
+	localSearch := &ga.LocalSearch{TopK: options.TopK, Interval: options.Interval, MaxPasses: options.MaxPasses}
+	if localSearch.TopK == 0 {
+		localSearch.TopK = 5
+	}
+	if localSearch.Interval == 0 {
+		localSearch.Interval = 10
+	}
+	return localSearch, nil
+}
+
+type islandOptions struct {
+	Count    int              `json:"count"`
+	Interval int              `json:"interval"` // number of generations between migrations
+	Migrants int              `json:"migrants"`
+	Topology string           `json:"topology"` // ring, full
+	Settings []islandSettings `json:"settings"` // operators of the first islands, the request ones by default
+}
+
+// islandSettings override the operators of the request on one island.
+type islandSettings struct {
+	Selection *selectionOptions `json:"selection"`
+	Crossover []string          `json:"crossover"`
+	Mutation  *mutationOptions  `json:"mutation"`
+}
+
+// parseIslandSettings returns the function configuring every island with its own operators, or nil when all
+// islands use the operators of the request.
+func parseIslandSettings(settings []islandSettings, islands int) (func(island int, ga *ga.GeneticAlgorithm), error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	if len(settings) > islands {
+		return nil, fmt.Errorf("settings of %d islands given for %d islands", len(settings), islands)
+	}
+	selections := make([]ga.SelectionStrategy, len(settings))
+	crossovers := make([][]ga.CrossoverOperator, len(settings))
+	mutations := make([]ga.MutationSelection, len(settings))
+	for i, options := range settings {
+		var err error
+		if options.Selection != nil {
+			if selections[i], err = parseSelection(options.Selection); err != nil {
+				return nil, fmt.Errorf("island %d: %s", i, err)
+			}
+		}
+		if crossovers[i], err = parseCrossover(options.Crossover); err != nil {
+			return nil, fmt.Errorf("island %d: %s", i, err)
+		}
+		if mutations[i], err = parseMutation(options.Mutation); err != nil {
+			return nil, fmt.Errorf("island %d: %s", i, err)
+		}
+	}
+	return func(island int, geneticAlgorithm *ga.GeneticAlgorithm) {
+		if island >= len(settings) {
+			return
+		}
+		if selections[island] != nil {
+			geneticAlgorithm.SetSelectionStrategy(selections[island])
+		}
+		if len(crossovers[island]) > 0 {
+			geneticAlgorithm.SetCrossoverOperators(crossovers[island]...)
+		}
+		if mutations[island] != nil {
+			geneticAlgorithm.SetMutationSelection(mutations[island])
+		}
+	}, nil
+}
+
+// parseIslands returns nil when the island model is not requested.
+func parseIslands(options *islandOptions) (*ga.IslandModel, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.Count < 0 || options.Interval < 0 || options.Migrants < 0 {
+		return nil, fmt.Errorf("island options cannot be negative")
+	}
+	model := &ga.IslandModel{Islands: options.Count, MigrationInterval: options.Interval, Migrants: options.Migrants}
+	if model.Islands == 0 {
+		model.Islands = 4
+	}
+	if model.MigrationInterval == 0 {
+		model.MigrationInterval = 10
+	}
+	if model.Migrants == 0 {
+		model.Migrants = 2
+	}
+	switch options.Topology {
+	case "", "ring":
+		model.Topology = ga.RingTopology{}
+	case "full":
+		model.Topology = ga.FullyConnectedTopology{}
+	default:
+		return nil, fmt.Errorf("unknown island topology %q", options.Topology)
+	}
+	configure, err := parseIslandSettings(options.Settings, model.Islands)
+	if err != nil {
+		return nil, err
+	}
+	model.Configure = configure
+	return model, nil
+}
+
+type seedingOptions struct {
+	Fraction   float64  `json:"fraction"`   // part of the initial population built by heuristics, 0.1 by default
+	Heuristics []string `json:"heuristics"` // greedy, nearest-neighbour, regret
+	Candidates int      `json:"candidates"`
+}
+
+var constructionHeuristics = map[string]ga.ConstructionHeuristic{
+	"greedy":            ga.GreedyConstruction{},
+	"nearest-neighbour": ga.NearestNeighbourConstruction{},
+	"regret":            ga.RegretInsertion{},
+}
+
+// parseSeeding returns nil when the initial population should be random.
+func parseSeeding(options *seedingOptions) (*ga.Seeding, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.Fraction < 0 || options.Fraction > 1 {
+		return nil, fmt.Errorf("seeding fraction must be between 0 and 1")
+	}
+	if options.Candidates < 0 {
+		return nil, fmt.Errorf("seeding candidates cannot be negative")
+	}
+	seeding := &ga.Seeding{Fraction: options.Fraction, Candidates: options.Candidates}
+	if seeding.Fraction == 0 {
+		seeding.Fraction = 0.1
+	}
+	for _, name := range options.Heuristics {
+		heuristic, ok := constructionHeuristics[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown construction heuristic %q", name)
+		}
+		seeding.Heuristics = append(seeding.Heuristics, heuristic)
+	}
+	return seeding, nil
+}
+
+type restartOptions struct {
+	Threshold float64 `json:"threshold"` // diversity below which the population is restarted
+	Elite     int     `json:"elite"`     // number of best solutions kept
+}
+
+// parseRestart returns nil when restarts are not requested.
+func parseRestart(options *restartOptions) (*ga.Restart, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.Threshold < 0 || options.Threshold > 1 {
+		return nil, fmt.Errorf("restart threshold must be between 0 and 1")
+	}
+	if options.Elite < 0 {
+		return nil, fmt.Errorf("restart elite cannot be negative")
+	}
+	restart := &ga.Restart{Threshold: options.Threshold, Elite: options.Elite}
+	if restart.Threshold == 0 {
+		restart.Threshold = 0.1
+	}
+	return restart, nil
+}
+
+type terminationOptions struct {
+	TimeBudget       int      `json:"timeBudget"` // milliseconds
+	Stagnation       int      `json:"stagnation"` // generations without improvement of the best objective
+	TargetObjective  *float64 `json:"targetObjective"`
+	MinimumDiversity float64  `json:"minimumDiversity"` // between 0 and 1
+}
+
+// parseTermination returns nil when the run should always do all iterations.
+func parseTermination(options *terminationOptions) (*ga.Termination, error) {
+	if options == nil {
+		return nil, nil
+	}
+	if options.TimeBudget < 0 || options.Stagnation < 0 {
+		return nil, fmt.Errorf("termination options cannot be negative")
+	}
+	if options.MinimumDiversity < 0 || options.MinimumDiversity > 1 {
+		return nil, fmt.Errorf("minimumDiversity must be between 0 and 1")
+	}
+	return &ga.Termination{
+		TimeBudget:            time.Duration(options.TimeBudget) * time.Millisecond,
+		StagnationGenerations: options.Stagnation,
+		TargetObjective:       options.TargetObjective,
+		MinimumDiversity:      options.MinimumDiversity,
+	}, nil
+}
+
+// parseAlternatives returns nil when alternatives are not requested.
+func parseAlternatives(ind *incomingData) (*ga.Alternatives, error) {
+	if ind.Alternatives < 0 {
+		return nil, fmt.Errorf("number of alternatives cannot be negative")
+	}
+	minimumDistance := 0.3
+	if ind.AlternativeDistance != nil {
+		minimumDistance = *ind.AlternativeDistance
+		if minimumDistance < 0 || minimumDistance > 1 {
+			return nil, fmt.Errorf("alternativeDistance must be between 0 and 1")
+		}
+	}
+	if ind.Alternatives == 0 {
+		return nil, nil
+	}
+	if ind.Pareto {
+		return nil, fmt.Errorf("alternatives cannot be combined with the Pareto front")
+	}
+	return &ga.Alternatives{
+		Count:           ind.Alternatives,
+		MinimumDistance: minimumDistance,
+		Ordering:        ind.AlternativeOrdering,
+	}, nil
+}
+
+// parseWarmStart checks the warm start options, the itinerary itself is read after POIs are added to the solver.
+func parseWarmStart(ind *incomingData) error {
+	if ind.WarmStartVariants != nil && *ind.WarmStartVariants < 0 {
+		return fmt.Errorf("warmStartVariants cannot be negative")
+	}
+	if ind.WarmStartVariants != nil && ind.WarmStart == nil {
+		return fmt.Errorf("warmStartVariants requires warmStart")
+	}
+	return nil
+}
+
+// warmStartVariants is the number of mutated copies of the warm start itinerary in the initial population.
+func warmStartVariants(ind *incomingData) int {
+	if ind.WarmStartVariants != nil {
+		return *ind.WarmStartVariants
+	}
+	return populationSize / 10
+}
+
+type stabilityOptions struct {
+	Weight       *float64         `json:"weight"`       // penalty for every changed visit, 0.05 by default
+	ShiftMinutes int              `json:"shiftMinutes"` // larger shifts of a visit count as changes, 30 by default
+	Reference    *ga.ApiItinerary `json:"reference"`    // the re-planned itinerary or the warm start by default
+}
+
+// parseStability returns nil when the plan does not have to stay close to a reference itinerary.
+func parseStability(ind *incomingData) (*ga.Stability, error) {
+	options := ind.Stability
+	if options == nil {
+		return nil, nil
+	}
+	stability := &ga.Stability{Weight: 0.05, ShiftMinutes: options.ShiftMinutes, Reference: options.Reference}
+	if options.Weight != nil {
+		stability.Weight = *options.Weight
+	}
+	if stability.Weight < 0 || stability.ShiftMinutes < 0 {
+		return nil, fmt.Errorf("stability options cannot be negative")
+	}
+	if stability.Reference == nil {
+		stability.Reference = ind.Itinerary
+	}
+	if stability.Reference == nil {
+		stability.Reference = ind.WarmStart
+	}
+	if stability.Reference == nil {
+		return nil, fmt.Errorf("stability requires a reference itinerary")
+	}
+	return stability, nil
+}
+
+type progressOptions struct {
+	Day         int           `json:"day"`  // index of the current day
+	Time        string        `json:"time"` // current time, 15:04
+	Position    ga.ApiPOI     `json:"position"`
+	Done        []ga.VisitRef `json:"done"`
+	Unavailable []ga.VisitRef `json:"unavailable"`
+}
+
+// parseProgress reads the progress of the trip re-planned by /replan. The current time is on the same day as
+// dayStart unless it is earlier, like the end of the day.
+func parseProgress(ind *incomingData, dayStart time.Time) (*ga.TripProgress, error) {
+	options := ind.Progress
+	now, err := time.Parse("15:04", options.Time)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time %q, expected HH:MM", options.Time)
+	}
+	if now.Before(dayStart) {
+		now = now.Add(24 * time.Hour)
+	}
+	if options.Position.Name == "" {
+		options.Position.Name = "Current position"
+	}
+	return &ga.TripProgress{
+		Itinerary:   ind.Itinerary,
+		Day:         options.Day,
+		Time:        now,
+		Position:    &ga.POI{Name: options.Position.Name, Lat: options.Position.Lat, Lon: options.Position.Lon},
+		Done:        options.Done,
+		Unavailable: options.Unavailable,
+	}, nil
+}
+
+type breakOptions struct {
+	Name        string `json:"name"`
+	MinDuration int    `json:"minDuration"`
+	MaxDuration int    `json:"maxDuration"` // minDuration by default
+	WindowStart string `json:"windowStart"` // 15:04
+	WindowEnd   string `json:"windowEnd"`
+}
+
+// parseBreaks reads the breaks taken every day. Like the end of the day, hours earlier than dayStart are on the
+// next day.
+func parseBreaks(ind *incomingData, dayStart time.Time) ([]ga.Break, error) {
+	breaks := make([]ga.Break, 0, len(ind.Breaks))
+	for i, options := range ind.Breaks {
+		b := ga.Break{Name: options.Name, MinDuration: options.MinDuration, MaxDuration: options.MaxDuration}
+		if b.Name == "" {
+			b.Name = fmt.Sprintf("Break %d", i+1)
+		}
+		if b.MaxDuration == 0 {
+			b.MaxDuration = b.MinDuration
+		}
+		var err error
+		if b.WindowStart, err = parseHourOfDay(options.WindowStart, dayStart); err != nil {
+			return nil, fmt.Errorf("break %q: %s", b.Name, err)
+		}
+		if b.WindowEnd, err = parseHourOfDay(options.WindowEnd, dayStart); err != nil {
+			return nil, fmt.Errorf("break %q: %s", b.Name, err)
+		}
+		breaks = append(breaks, b)
+	}
+	return breaks, nil
+}
+
+// parseHourOfDay reads an hour of a day which begins at dayStart, earlier hours are on the next day.
+func parseHourOfDay(value string, dayStart time.Time) (time.Time, error) {
+	hour, err := time.Parse("15:04", value)
+	if err != nil {
+		return hour, fmt.Errorf("invalid hour %q, expected HH:MM", value)
+	}
+	if hour.Before(dayStart) {
+		hour = hour.Add(24 * time.Hour)
+	}
+	return hour, nil
+}
+
+// exactSolverMaxDays is the largest number of days of requests which are solved optimally when they do not choose
+// a solver.
+const exactSolverMaxDays = 2
+
+// createSolver creates the solver chosen in the request. By default small requests are solved optimally with the
+// exact solver and the other ones with the genetic algorithm. Options of the genetic algorithm are rejected for
+// other solvers.
+func createSolver(ind *incomingData, dayStart, dayEnd time.Time) (ga.Solver, error) {
+	gaOptions := ind.Selection != nil || ind.Replacement != nil || len(ind.Crossover) > 0 || ind.Mutation != nil ||
+		ind.LocalSearch != nil || ind.Islands != nil || ind.Termination != nil || ind.Restart != nil ||
+		ind.Seeding != nil || ind.Alternatives != 0 || ind.AlternativeDistance != nil ||
+		ind.AlternativeOrdering || ind.WarmStart != nil || ind.WarmStartVariants != nil || ind.Itinerary != nil ||
+		ind.Stability != nil || ind.Progress != nil || len(ind.Breaks) > 0
+	solver := ind.Solver
+	if solver == "" {
+		solver = "ga"
+		if !gaOptions && !ind.Pareto && len(ind.PoiList) <= ga.ExactSolverMaxPois && len(ind.Days) <= exactSolverMaxDays {
+			solver = "exact"
+		}
+	}
+	if ind.SolverOptions != nil && solver != "ils" && solver != "sa" && solver != "aco" {
+		return nil, fmt.Errorf("solverOptions are only accepted by the ils, sa and aco solvers")
+	}
+	if solver == "ga" {
+		geneticAlgorithm := ga.CreateGeneticAlgorithm(dayStart, dayEnd, ind.Days, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier)
+		geneticAlgorithm.SetRunParameters(populationSize, iterations, solutionTTL)
+		if err := configureAlgorithm(geneticAlgorithm, ind); err != nil {
+			return nil, err
+		}
+		return geneticAlgorithm, nil
+	}
+	if gaOptions {
+		return nil, fmt.Errorf("solver %q does not accept options of the genetic algorithm", solver)
+	}
+	if ind.Pareto {
+		return nil, fmt.Errorf("the Pareto front is only available with the ga solver")
+	}
+	switch solver {
+	case "ils":
+		iteratedLocalSearch := ga.CreateIteratedLocalSearch(dayStart, dayEnd, ind.Days, poiMultiplier,
+			penaltyMultiplier, satisfactionMultiplier)
+		return iteratedLocalSearch, configureIteratedLocalSearch(iteratedLocalSearch, ind.SolverOptions)
+	case "sa":
+		simulatedAnnealing := ga.CreateSimulatedAnnealing(dayStart, dayEnd, ind.Days, poiMultiplier,
+			penaltyMultiplier, satisfactionMultiplier)
+		return simulatedAnnealing, configureSimulatedAnnealing(simulatedAnnealing, ind.SolverOptions)
+	case "aco":
+		antColony := ga.CreateAntColonyOptimization(dayStart, dayEnd, ind.Days, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier)
+		return antColony, configureAntColony(antColony, ind.SolverOptions)
+	case "exact":
+		if len(ind.PoiList) > ga.ExactSolverMaxPois {
+			return nil, fmt.Errorf("exact solver accepts at most %d POIs", ga.ExactSolverMaxPois)
+		}
+		return ga.CreateExactSolver(dayStart, dayEnd, ind.Days, poiMultiplier, penaltyMultiplier,
+			satisfactionMultiplier), nil
+	}
+	return nil, fmt.Errorf("unknown solver %q", ind.Solver)
+}
+
+type solverOptions struct {
+	Iterations         int      `json:"iterations"`         // steps of sa, perturbations of ils, iterations of aco
+	InitialTemperature *float64 `json:"initialTemperature"` // sa, the value of one POI in the objective by default
+	CoolingRate        *float64 `json:"coolingRate"`        // sa, between 0 and 1
+	Ants               int      `json:"ants"`               // aco
+}
+
+// checkSolverOptions rejects negative values and the options which are not used by the solver.
+func checkSolverOptions(options *solverOptions, solver string) error {
+	if options.Iterations < 0 || options.Ants < 0 ||
+		options.InitialTemperature != nil && *options.InitialTemperature < 0 {
+		return fmt.Errorf("solverOptions cannot be negative")
+	}
+	if options.CoolingRate != nil && (*options.CoolingRate <= 0 || *options.CoolingRate > 1) {
+		return fmt.Errorf("coolingRate must be greater than 0 and at most 1")
+	}
+	if solver != "sa" && (options.InitialTemperature != nil || options.CoolingRate != nil) {
+		return fmt.Errorf("the temperature is only used by the sa solver")
+	}
+	if solver != "aco" && options.Ants != 0 {
+		return fmt.Errorf("ants are only used by the aco solver")
+	}
+	return nil
+}
+
+func configureIteratedLocalSearch(iteratedLocalSearch *ga.IteratedLocalSearch, options *solverOptions) error {
+	if options == nil {
+		return nil
+	}
+	if err := checkSolverOptions(options, "ils"); err != nil {
+		return err
+	}
+	if options.Iterations > 0 {
+		iteratedLocalSearch.SetIterations(options.Iterations)
+	}
+	return nil
+}
+
+func configureSimulatedAnnealing(simulatedAnnealing *ga.SimulatedAnnealing, options *solverOptions) error {
+	if options == nil {
+		return nil
+	}
+	if err := checkSolverOptions(options, "sa"); err != nil {
+		return err
+	}
+	if options.Iterations > 0 {
+		simulatedAnnealing.SetIterations(options.Iterations)
+	}
+	initialTemperature, coolingRate := poiMultiplier, ga.DefaultCoolingRate
+	if options.InitialTemperature != nil {
+		initialTemperature = *options.InitialTemperature
+	}
+	if options.CoolingRate != nil {
+		coolingRate = *options.CoolingRate
+	}
+	simulatedAnnealing.SetTemperature(initialTemperature, coolingRate)
+	return nil
+}
+
+func configureAntColony(antColony *ga.AntColonyOptimization, options *solverOptions) error {
+	if options == nil {
+		return nil
+	}
+	if err := checkSolverOptions(options, "aco"); err != nil {
+		return err
+	}
+	antColony.SetColony(options.Ants, options.Iterations)
+	return nil
+}
+
+// paretoIgnoredOption returns the name of an option of the request which the Pareto front does not use, or an empty
+// string if there is none, see SolvePareto.
+func paretoIgnoredOption(ind *incomingData) string {
+	switch {
+	case ind.Selection != nil:
+		return "selection"
+	case ind.Replacement != nil:
+		return "replacement"
+	case ind.LocalSearch != nil:
+		return "localSearch"
+	case ind.Islands != nil:
+		return "islands"
+	case ind.Restart != nil:
+		return "restart"
+	case ind.Stability != nil:
+		return "stability"
+	case ind.Termination != nil && ind.Termination.Stagnation != 0:
+		return "termination.stagnation"
+	case ind.Termination != nil && ind.Termination.TargetObjective != nil:
+		return "termination.targetObjective"
+	}
+	return ""
+}
+
+// configureAlgorithm applies the optional algorithm settings from the request.
+func configureAlgorithm(geneticAlgorithm *ga.GeneticAlgorithm, ind *incomingData) error {
+	if option := paretoIgnoredOption(ind); ind.Pareto && option != "" {
+		return fmt.Errorf("%s cannot be combined with the Pareto front", option)
+	}
+	selection, err := parseSelection(ind.Selection)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetSelectionStrategy(selection)
+
+	replacement, err := parseReplacement(ind.Replacement)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetReplacementStrategy(replacement)
+
+	crossover, err := parseCrossover(ind.Crossover)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetCrossoverOperators(crossover...)
+
+	mutation, err := parseMutation(ind.Mutation)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetMutationSelection(mutation)
+
+	localSearch, err := parseLocalSearch(ind.LocalSearch)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetLocalSearch(localSearch)
+
+	alternatives, err := parseAlternatives(ind)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetAlternatives(alternatives)
+
+	islandModel, err := parseIslands(ind.Islands)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetIslandModel(islandModel)
+
+	termination, err := parseTermination(ind.Termination)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetTermination(termination)
+
+	restart, err := parseRestart(ind.Restart)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetRestart(restart)
+
+	seeding, err := parseSeeding(ind.Seeding)
+	if err != nil {
+		return err
+	}
+	geneticAlgorithm.SetSeeding(seeding)
+	return parseWarmStart(ind)
+}
@@ -0,0 +1,272 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+const earthRadiusMeters = 6371000.0
+const gridCellDegrees = 0.002
+const maxSnapRings = 50
+
+type Coordinate struct {
+	Lat float64
+	Lon float64
+}
+
+// Graph is an undirected pedestrian graph stored in compressed sparse row form.
+type Graph struct {
+	coordinates []Coordinate
+	offsets     []int32
+	targets     []int32
+	lengths     []float32
+	grid        map[gridCell][]int32
+}
+
+type gridCell struct {
+	x int32
+	y int32
+}
+
+var walkableHighways = map[string]bool{
+	"footway":        true,
+	"pedestrian":     true,
+	"path":           true,
+	"steps":          true,
+	"corridor":       true,
+	"living_street":  true,
+	"residential":    true,
+	"service":        true,
+	"unclassified":   true,
+	"road":           true,
+	"track":          true,
+	"cycleway":       true,
+	"bridleway":      true,
+	"tertiary":       true,
+	"tertiary_link":  true,
+	"secondary":      true,
+	"secondary_link": true,
+	"primary":        true,
+	"primary_link":   true,
+}
+
+func isWalkable(tags map[string]string) bool {
+	highway, ok := tags["highway"]
+	if !ok {
+		return false
+	}
+	switch tags["foot"] {
+	case "no", "private":
+		return false
+	case "yes", "designated", "permissive":
+		return true
+	}
+	switch tags["access"] {
+	case "no", "private":
+		return false
+	}
+	return walkableHighways[highway]
+}
+
+func distanceMeters(a, b Coordinate) float64 {
+	lat1 := a.Lat * math.Pi / 180
+	lat2 := b.Lat * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// LoadGraph reads an .osm.pbf extract and builds the pedestrian graph. The file is read twice: first the walkable
+// ways are collected, then only the nodes referenced by them are kept.
+func LoadGraph(path string) (*Graph, error) {
+	nodeIndex := make(map[int64]int32)
+	ways := make([][]int64, 0)
+
+	err := readPBFFile(path, pbfHandler{way: func(w osmWay) {
+		if len(w.refs) < 2 || !isWalkable(w.tags) {
+			return
+		}
+		for _, ref := range w.refs {
+			if _, ok := nodeIndex[ref]; !ok {
+				nodeIndex[ref] = int32(len(nodeIndex))
+			}
+		}
+		ways = append(ways, w.refs)
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if len(ways) == 0 {
+		return nil, errors.New("routing: extract contains no walkable ways")
+	}
+
+	coordinates := make([]Coordinate, len(nodeIndex))
+	found := make([]bool, len(nodeIndex))
+	err = readPBFFile(path, pbfHandler{node: func(n osmNode) {
+		if index, ok := nodeIndex[n.id]; ok {
+			coordinates[index] = Coordinate{Lat: n.lat, Lon: n.lon}
+			found[index] = true
+		}
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	edges := make([]graphEdge, 0)
+	for _, refs := range ways {
+		for i := 1; i < len(refs); i++ {
+			from, to := nodeIndex[refs[i-1]], nodeIndex[refs[i]]
+			if from == to || !found[from] || !found[to] {
+				continue
+			}
+			edges = append(edges, graphEdge{from: from, to: to,
+				length: float32(distanceMeters(coordinates[from], coordinates[to]))})
+		}
+	}
+	return newGraph(coordinates, edges), nil
+}
+
+type graphEdge struct {
+	from, to int32
+	length   float32
+}
+
+// newGraph stores the undirected edges between the nodes with the given coordinates.
+func newGraph(coordinates []Coordinate, edges []graphEdge) *Graph {
+	graph := &Graph{
+		coordinates: coordinates,
+		offsets:     make([]int32, len(coordinates)+1),
+		targets:     make([]int32, 2*len(edges)),
+		lengths:     make([]float32, 2*len(edges)),
+	}
+	for _, e := range edges {
+		graph.offsets[e.from+1]++
+		graph.offsets[e.to+1]++
+	}
+	for i := 1; i < len(graph.offsets); i++ {
+		graph.offsets[i] += graph.offsets[i-1]
+	}
+	fill := make([]int32, len(coordinates))
+	copy(fill, graph.offsets[:len(coordinates)])
+	for _, e := range edges {
+		graph.targets[fill[e.from]], graph.lengths[fill[e.from]] = e.to, e.length
+		fill[e.from]++
+		graph.targets[fill[e.to]], graph.lengths[fill[e.to]] = e.from, e.length
+		fill[e.to]++
+	}
+
+	graph.buildGrid()
+	return graph
+}
+
+func readPBFFile(path string, handler pbfHandler) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := readPBF(file, handler); err != nil {
+		return fmt.Errorf("routing: reading %s: %w", path, err)
+	}
+	return nil
+}
+
+// NodeCount returns the number of graph nodes.
+func (g *Graph) NodeCount() int {
+	return len(g.coordinates)
+}
+
+// EdgeCount returns the number of undirected edges.
+func (g *Graph) EdgeCount() int {
+	return len(g.targets) / 2
+}
+
+// largestComponent marks the nodes of the biggest connected component, so that points are never snapped to an
+// isolated piece of footway from which nothing else can be reached.
+func (g *Graph) largestComponent() []bool {
+	component := make([]int32, len(g.coordinates))
+	for i := range component {
+		component[i] = -1
+	}
+	var best, bestSize int32 = -1, 0
+	stack := make([]int32, 0)
+	for start := range g.coordinates {
+		if component[start] >= 0 {
+			continue
+		}
+		id := int32(start)
+		size := int32(0)
+		component[start] = id
+		stack = append(stack[:0], id)
+		for len(stack) > 0 {
+			node := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for e := g.offsets[node]; e < g.offsets[node+1]; e++ {
+				if next := g.targets[e]; component[next] < 0 {
+					component[next] = id
+					stack = append(stack, next)
+				}
+			}
+		}
+		if size > bestSize {
+			best, bestSize = id, size
+		}
+	}
+	result := make([]bool, len(g.coordinates))
+	for i, c := range component {
+		result[i] = c == best
+	}
+	return result
+}
+
+func cellOf(c Coordinate) gridCell {
+	return gridCell{x: int32(math.Floor(c.Lon / gridCellDegrees)), y: int32(math.Floor(c.Lat / gridCellDegrees))}
+}
+
+func (g *Graph) buildGrid() {
+	g.grid = make(map[gridCell][]int32)
+	for node, inComponent := range g.largestComponent() {
+		if !inComponent {
+			continue
+		}
+		cell := cellOf(g.coordinates[node])
+		g.grid[cell] = append(g.grid[cell], int32(node))
+	}
+}
+
+// nearestNode returns the graph node closest to the given point. Grid rings around the point are searched until
+// a node is found and one more ring has been checked, since a node in the next ring can still be closer.
+func (g *Graph) nearestNode(point Coordinate) (int32, float64, error) {
+	center := cellOf(point)
+	best := int32(-1)
+	bestDistance := math.Inf(1)
+	foundRing := int32(-1)
+	for ring := int32(0); ring <= maxSnapRings; ring++ {
+		for x := center.x - ring; x <= center.x+ring; x++ {
+			for y := center.y - ring; y <= center.y+ring; y++ {
+				if x != center.x-ring && x != center.x+ring && y != center.y-ring && y != center.y+ring {
+					continue
+				}
+				for _, node := range g.grid[gridCell{x: x, y: y}] {
+					if d := distanceMeters(point, g.coordinates[node]); d < bestDistance {
+						best, bestDistance = node, d
+					}
+				}
+			}
+		}
+		if best >= 0 && foundRing < 0 {
+			foundRing = ring
+		}
+		if foundRing >= 0 && ring > foundRing {
+			return best, bestDistance, nil
+		}
+	}
+	if best >= 0 {
+		return best, bestDistance, nil
+	}
+	return -1, 0, fmt.Errorf("routing: no walkable way near (%f, %f)", point.Lat, point.Lon)
+}
@@ -0,0 +1,567 @@
+package routing
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Minimal reader of the OpenStreetMap PBF format (https://wiki.openstreetmap.org/wiki/PBF_Format).
+// Only the parts needed to build a pedestrian graph are decoded: nodes, dense nodes and ways.
+
+const maxBlobHeaderSize = 64 * 1024
+const maxBlobSize = 32 * 1024 * 1024
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("routing: truncated protobuf message")
+
+type wireReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *wireReader) done() bool {
+	return r.pos >= len(r.buf)
+}
+
+func (r *wireReader) varint() (uint64, error) {
+	var value uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		if r.pos >= len(r.buf) {
+			return 0, errTruncated
+		}
+		b := r.buf[r.pos]
+		r.pos++
+		value |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return value, nil
+		}
+	}
+	return 0, errors.New("routing: varint overflow")
+}
+
+func (r *wireReader) next() (field int, wireType int, err error) {
+	key, err := r.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(key >> 3), int(key & 7), nil
+}
+
+func (r *wireReader) bytes() ([]byte, error) {
+	length, err := r.varint()
+	if err != nil {
+		return nil, err
+	}
+	end := r.pos + int(length)
+	if length > uint64(len(r.buf)) || end > len(r.buf) {
+		return nil, errTruncated
+	}
+	data := r.buf[r.pos:end]
+	r.pos = end
+	return data, nil
+}
+
+func (r *wireReader) skip(wireType int) error {
+	switch wireType {
+	case wireVarint:
+		_, err := r.varint()
+		return err
+	case wireFixed64:
+		r.pos += 8
+	case wireBytes:
+		_, err := r.bytes()
+		return err
+	case wireFixed32:
+		r.pos += 4
+	default:
+		return fmt.Errorf("routing: unsupported wire type %d", wireType)
+	}
+	if r.pos > len(r.buf) {
+		return errTruncated
+	}
+	return nil
+}
+
+func zigzag(value uint64) int64 {
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+// readVarints reads a repeated varint field which may be either packed or not.
+func readVarints(r *wireReader, wireType int, dst []uint64) ([]uint64, error) {
+	if wireType == wireVarint {
+		value, err := r.varint()
+		if err != nil {
+			return dst, err
+		}
+		return append(dst, value), nil
+	}
+	data, err := r.bytes()
+	if err != nil {
+		return dst, err
+	}
+	packed := wireReader{buf: data}
+	for !packed.done() {
+		value, err := packed.varint()
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, value)
+	}
+	return dst, nil
+}
+
+type osmNode struct {
+	id   int64
+	lat  float64
+	lon  float64
+	tags map[string]string
+}
+
+type osmWay struct {
+	id   int64
+	refs []int64
+	tags map[string]string
+}
+
+// pbfHandler receives decoded elements. Either callback may be nil to skip decoding that element type.
+type pbfHandler struct {
+	node func(n osmNode)
+	way  func(w osmWay)
+}
+
+func readBlob(reader io.Reader) (blobType string, data []byte, err error) {
+	var sizeBuf [4]byte
+	if _, err = io.ReadFull(reader, sizeBuf[:]); err != nil {
+		return "", nil, err
+	}
+	headerSize := binary.BigEndian.Uint32(sizeBuf[:])
+	if headerSize > maxBlobHeaderSize {
+		return "", nil, fmt.Errorf("routing: blob header too large (%d bytes)", headerSize)
+	}
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return "", nil, err
+	}
+
+	var dataSize uint64
+	r := wireReader{buf: header}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return "", nil, err
+		}
+		switch field {
+		case 1:
+			value, err := r.bytes()
+			if err != nil {
+				return "", nil, err
+			}
+			blobType = string(value)
+		case 3:
+			if dataSize, err = r.varint(); err != nil {
+				return "", nil, err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	if dataSize > maxBlobSize {
+		return "", nil, fmt.Errorf("routing: blob too large (%d bytes)", dataSize)
+	}
+	blob := make([]byte, dataSize)
+	if _, err = io.ReadFull(reader, blob); err != nil {
+		return "", nil, err
+	}
+	data, err = decompressBlob(blob)
+	return blobType, data, err
+}
+
+func decompressBlob(blob []byte) ([]byte, error) {
+	var rawSize uint64
+	r := wireReader{buf: blob}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case 1:
+			return r.bytes()
+		case 2:
+			if rawSize, err = r.varint(); err != nil {
+				return nil, err
+			}
+		case 3:
+			compressed, err := r.bytes()
+			if err != nil {
+				return nil, err
+			}
+			if rawSize > maxBlobSize {
+				return nil, fmt.Errorf("routing: blob too large (%d bytes uncompressed)", rawSize)
+			}
+			zr, err := zlib.NewReader(bytes.NewReader(compressed))
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			out := bytes.NewBuffer(make([]byte, 0, rawSize))
+			// one byte more than allowed is read to tell a blob of the maximum size from a larger one
+			if _, err := io.Copy(out, io.LimitReader(zr, maxBlobSize+1)); err != nil {
+				return nil, err
+			}
+			if out.Len() > maxBlobSize {
+				return nil, fmt.Errorf("routing: blob too large (more than %d bytes uncompressed)", maxBlobSize)
+			}
+			return out.Bytes(), nil
+		case 4, 5, 6, 7:
+			return nil, fmt.Errorf("routing: unsupported blob compression (field %d), only raw and zlib are supported", field)
+		default:
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, errors.New("routing: empty blob")
+}
+
+func checkHeaderBlock(data []byte) error {
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		if field != 4 {
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+			continue
+		}
+		feature, err := r.bytes()
+		if err != nil {
+			return err
+		}
+		switch string(feature) {
+		case "OsmSchema-V0.6", "DenseNodes":
+		default:
+			return fmt.Errorf("routing: unsupported PBF feature %q", feature)
+		}
+	}
+	return nil
+}
+
+// readPBF streams the file and calls the handler for every node and way.
+func readPBF(reader io.Reader, handler pbfHandler) error {
+	for {
+		blobType, data, err := readBlob(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch blobType {
+		case "OSMHeader":
+			if err := checkHeaderBlock(data); err != nil {
+				return err
+			}
+		case "OSMData":
+			if err := decodePrimitiveBlock(data, handler); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type primitiveBlock struct {
+	strings     []string
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (b *primitiveBlock) lat(value int64) float64 {
+	return 1e-9 * float64(b.latOffset+b.granularity*value)
+}
+
+func (b *primitiveBlock) lon(value int64) float64 {
+	return 1e-9 * float64(b.lonOffset+b.granularity*value)
+}
+
+func (b *primitiveBlock) tags(keys, vals []uint64) map[string]string {
+	if len(keys) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(keys))
+	for i := 0; i < len(keys) && i < len(vals); i++ {
+		if keys[i] < uint64(len(b.strings)) && vals[i] < uint64(len(b.strings)) {
+			tags[b.strings[keys[i]]] = b.strings[vals[i]]
+		}
+	}
+	return tags
+}
+
+func decodePrimitiveBlock(data []byte, handler pbfHandler) error {
+	block := primitiveBlock{granularity: 100}
+	groups := make([][]byte, 0)
+
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			table, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			if block.strings, err = decodeStringTable(table); err != nil {
+				return err
+			}
+		case 2:
+			group, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			groups = append(groups, group)
+		case 17, 19, 20:
+			value, err := r.varint()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 17:
+				block.granularity = int64(value)
+			case 19:
+				block.latOffset = int64(value)
+			case 20:
+				block.lonOffset = int64(value)
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, group := range groups {
+		if err := decodePrimitiveGroup(&block, group, handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeStringTable(data []byte) ([]string, error) {
+	strings := make([]string, 0)
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return nil, err
+		}
+		if field != 1 {
+			if err := r.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		value, err := r.bytes()
+		if err != nil {
+			return nil, err
+		}
+		strings = append(strings, string(value))
+	}
+	return strings, nil
+}
+
+func decodePrimitiveGroup(block *primitiveBlock, data []byte, handler pbfHandler) error {
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		switch {
+		case field == 1 && handler.node != nil:
+			message, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			if err := decodeNode(block, message, handler.node); err != nil {
+				return err
+			}
+		case field == 2 && handler.node != nil:
+			message, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			if err := decodeDenseNodes(block, message, handler.node); err != nil {
+				return err
+			}
+		case field == 3 && handler.way != nil:
+			message, err := r.bytes()
+			if err != nil {
+				return err
+			}
+			if err := decodeWay(block, message, handler.way); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func decodeNode(block *primitiveBlock, data []byte, callback func(osmNode)) error {
+	var node osmNode
+	var keys, vals []uint64
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1, 8, 9:
+			value, err := r.varint()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 1:
+				node.id = zigzag(value)
+			case 8:
+				node.lat = block.lat(zigzag(value))
+			case 9:
+				node.lon = block.lon(zigzag(value))
+			}
+		case 2:
+			if keys, err = readVarints(&r, wireType, keys); err != nil {
+				return err
+			}
+		case 3:
+			if vals, err = readVarints(&r, wireType, vals); err != nil {
+				return err
+			}
+		default:
+			if err := r.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	node.tags = block.tags(keys, vals)
+	callback(node)
+	return nil
+}
+
+func decodeDenseNodes(block *primitiveBlock, data []byte, callback func(osmNode)) error {
+	var ids, lats, lons, keysVals []uint64
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			ids, err = readVarints(&r, wireType, ids)
+		case 8:
+			lats, err = readVarints(&r, wireType, lats)
+		case 9:
+			lons, err = readVarints(&r, wireType, lons)
+		case 10:
+			keysVals, err = readVarints(&r, wireType, keysVals)
+		default:
+			err = r.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return errors.New("routing: dense nodes with inconsistent lengths")
+	}
+
+	var id, lat, lon int64
+	kv := 0
+	for i := range ids {
+		id += zigzag(ids[i])
+		lat += zigzag(lats[i])
+		lon += zigzag(lons[i])
+		node := osmNode{id: id, lat: block.lat(lat), lon: block.lon(lon)}
+		// keys_vals holds key/value string ids per node, each node terminated with 0
+		for kv < len(keysVals) && keysVals[kv] != 0 {
+			if kv+1 < len(keysVals) {
+				if node.tags == nil {
+					node.tags = make(map[string]string)
+				}
+				key, val := keysVals[kv], keysVals[kv+1]
+				if key < uint64(len(block.strings)) && val < uint64(len(block.strings)) {
+					node.tags[block.strings[key]] = block.strings[val]
+				}
+			}
+			kv += 2
+		}
+		kv++
+		callback(node)
+	}
+	return nil
+}
+
+func decodeWay(block *primitiveBlock, data []byte, callback func(osmWay)) error {
+	var way osmWay
+	var keys, vals, refs []uint64
+	r := wireReader{buf: data}
+	for !r.done() {
+		field, wireType, err := r.next()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			value, err := r.varint()
+			if err != nil {
+				return err
+			}
+			way.id = int64(value)
+		case 2:
+			keys, err = readVarints(&r, wireType, keys)
+		case 3:
+			vals, err = readVarints(&r, wireType, vals)
+		case 8:
+			refs, err = readVarints(&r, wireType, refs)
+		default:
+			err = r.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	way.tags = block.tags(keys, vals)
+	way.refs = make([]int64, len(refs))
+	var ref int64
+	for i, value := range refs {
+		ref += zigzag(value)
+		way.refs[i] = ref
+	}
+	callback(way)
+	return nil
+}
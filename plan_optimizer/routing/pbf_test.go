@@ -0,0 +1,332 @@
+package routing
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// protobuf encoding of the test fixtures
+
+func appendVarint(buf []byte, value uint64) []byte {
+	for value >= 0x80 {
+		buf = append(buf, byte(value)|0x80)
+		value >>= 7
+	}
+	return append(buf, byte(value))
+}
+
+func zigzagEncode(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+type message []byte
+
+func (m message) varint(field int, value uint64) message {
+	return appendVarint(appendVarint(m, uint64(field<<3|wireVarint)), value)
+}
+
+func (m message) bytes(field int, data []byte) message {
+	m = appendVarint(appendVarint(m, uint64(field<<3|wireBytes)), uint64(len(data)))
+	return append(m, data...)
+}
+
+func (m message) packed(field int, values []uint64) message {
+	var data []byte
+	for _, value := range values {
+		data = appendVarint(data, value)
+	}
+	return m.bytes(field, data)
+}
+
+// deltas encodes the values as the zigzag deltas used by dense nodes and way refs.
+func deltas(values ...int64) []uint64 {
+	encoded := make([]uint64, len(values))
+	var previous int64
+	for i, value := range values {
+		encoded[i] = zigzagEncode(value - previous)
+		previous = value
+	}
+	return encoded
+}
+
+func zlibBlob(t testing.TB, data []byte, rawSize int) []byte {
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return message(nil).varint(2, uint64(rawSize)).bytes(3, compressed.Bytes())
+}
+
+func appendFileBlock(file []byte, blobType string, blob []byte) []byte {
+	header := message(nil).bytes(1, []byte(blobType)).varint(3, uint64(len(blob)))
+	file = binary.BigEndian.AppendUint32(file, uint32(len(header)))
+	file = append(file, header...)
+	return append(file, blob...)
+}
+
+type testNode struct {
+	id       int64
+	lat, lon float64
+	tags     map[string]string
+}
+
+type testWay struct {
+	id   int64
+	refs []int64
+	tags map[string]string
+}
+
+// encodePBF builds a file with a zlib compressed header block and a raw data block holding the nodes as dense nodes
+// and the ways.
+func encodePBF(t testing.TB, nodes []testNode, ways []testWay) []byte {
+	strings := []string{""}
+	index := map[string]uint64{"": 0}
+	stringId := func(value string) uint64 {
+		if id, ok := index[value]; ok {
+			return id
+		}
+		index[value] = uint64(len(strings))
+		strings = append(strings, value)
+		return index[value]
+	}
+
+	var ids, lats, lons []int64
+	var keysVals []uint64
+	for _, node := range nodes {
+		ids = append(ids, node.id)
+		lats = append(lats, int64(math.Round(node.lat*1e7)))
+		lons = append(lons, int64(math.Round(node.lon*1e7)))
+		for key, value := range node.tags {
+			keysVals = append(keysVals, stringId(key), stringId(value))
+		}
+		keysVals = append(keysVals, 0)
+	}
+	dense := message(nil).packed(1, deltas(ids...)).packed(8, deltas(lats...)).packed(9, deltas(lons...)).
+		packed(10, keysVals)
+	group := message(nil).bytes(2, dense)
+	for _, way := range ways {
+		var keys, vals []uint64
+		for key, value := range way.tags {
+			keys = append(keys, stringId(key))
+			vals = append(vals, stringId(value))
+		}
+		encoded := message(nil).varint(1, uint64(way.id)).packed(2, keys).packed(3, vals).packed(8, deltas(way.refs...))
+		group = group.bytes(3, encoded)
+	}
+
+	var table message
+	for _, value := range strings {
+		table = table.bytes(1, []byte(value))
+	}
+	block := message(nil).bytes(1, table).bytes(2, group).varint(17, 100)
+
+	header := message(nil).bytes(4, []byte("OsmSchema-V0.6")).bytes(4, []byte("DenseNodes"))
+	file := appendFileBlock(nil, "OSMHeader", zlibBlob(t, header, len(header)))
+	return appendFileBlock(file, "OSMData", message(nil).bytes(1, block))
+}
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		encoded []byte
+		value   uint64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x01}, 1},
+		{[]byte{0x7f}, 127},
+		{[]byte{0x80, 0x01}, 128},
+		{[]byte{0xac, 0x02}, 300},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}, math.MaxUint64},
+	}
+	for _, test := range tests {
+		r := wireReader{buf: test.encoded}
+		value, err := r.varint()
+		if err != nil || value != test.value || !r.done() {
+			t.Errorf("varint(% x) = %d, %v, want %d", test.encoded, value, err, test.value)
+		}
+		if encoded := appendVarint(nil, test.value); !bytes.Equal(encoded, test.encoded) {
+			t.Errorf("appendVarint(%d) = % x, want % x", test.value, encoded, test.encoded)
+		}
+	}
+
+	r := wireReader{buf: []byte{0x80, 0x80}}
+	if _, err := r.varint(); err != errTruncated {
+		t.Errorf("truncated varint: got %v, want %v", err, errTruncated)
+	}
+	r = wireReader{buf: bytes.Repeat([]byte{0xff}, 11)}
+	if _, err := r.varint(); err == nil {
+		t.Error("varint longer than 64 bits was accepted")
+	}
+}
+
+func TestZigzag(t *testing.T) {
+	tests := []struct {
+		encoded uint64
+		value   int64
+	}{
+		{0, 0},
+		{1, -1},
+		{2, 1},
+		{3, -2},
+		{4294967294, 2147483647},
+		{4294967295, -2147483648},
+		{math.MaxUint64 - 1, math.MaxInt64},
+		{math.MaxUint64, math.MinInt64},
+	}
+	for _, test := range tests {
+		if value := zigzag(test.encoded); value != test.value {
+			t.Errorf("zigzag(%d) = %d, want %d", test.encoded, value, test.value)
+		}
+	}
+}
+
+func TestReadVarintsPackedAndUnpacked(t *testing.T) {
+	encoded := message(nil).varint(8, 5).packed(8, []uint64{300, 1}).varint(8, 7)
+	r := wireReader{buf: encoded}
+	var values []uint64
+	for !r.done() {
+		_, wireType, err := r.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if values, err = readVarints(&r, wireType, values); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []uint64{5, 300, 1, 7}
+	if len(values) != len(want) {
+		t.Fatalf("got %v, want %v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Fatalf("got %v, want %v", values, want)
+		}
+	}
+}
+
+var fixtureNodes = []testNode{
+	{id: 100, lat: 50.0600, lon: 19.9300},
+	{id: 101, lat: 50.0610, lon: 19.9310, tags: map[string]string{"amenity": "bench"}},
+	{id: 95, lat: 50.0620, lon: 19.9300},
+	{id: 200, lat: -33.8688, lon: 151.2093},
+	{id: 102, lat: 50.0630, lon: 19.9320},
+	{id: 103, lat: 50.0640, lon: 19.9330},
+}
+
+var fixtureWays = []testWay{
+	{id: 1, refs: []int64{100, 101, 95}, tags: map[string]string{"highway": "footway"}},
+	{id: 2, refs: []int64{95, 102}, tags: map[string]string{"highway": "motorway"}},
+	{id: 3, refs: []int64{95, 102}, tags: map[string]string{"highway": "residential", "foot": "no"}},
+	{id: 4, refs: []int64{102, 103}, tags: map[string]string{"building": "yes"}},
+	{id: 5, refs: []int64{95, 102, 103}, tags: map[string]string{"highway": "primary", "foot": "yes"}},
+}
+
+func TestReadPBF(t *testing.T) {
+	file := encodePBF(t, fixtureNodes, fixtureWays)
+	var nodes []osmNode
+	var ways []osmWay
+	err := readPBF(bytes.NewReader(file), pbfHandler{
+		node: func(n osmNode) { nodes = append(nodes, n) },
+		way:  func(w osmWay) { ways = append(ways, w) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nodes) != len(fixtureNodes) {
+		t.Fatalf("got %d nodes, want %d", len(nodes), len(fixtureNodes))
+	}
+	for i, want := range fixtureNodes {
+		got := nodes[i]
+		if got.id != want.id || math.Abs(got.lat-want.lat) > 1e-9 || math.Abs(got.lon-want.lon) > 1e-9 {
+			t.Errorf("node %d = %d (%f, %f), want %d (%f, %f)", i, got.id, got.lat, got.lon, want.id, want.lat, want.lon)
+		}
+		if len(got.tags) != len(want.tags) {
+			t.Errorf("node %d has tags %v, want %v", want.id, got.tags, want.tags)
+		}
+		for key, value := range want.tags {
+			if got.tags[key] != value {
+				t.Errorf("node %d has tags %v, want %v", want.id, got.tags, want.tags)
+			}
+		}
+	}
+
+	if len(ways) != len(fixtureWays) {
+		t.Fatalf("got %d ways, want %d", len(ways), len(fixtureWays))
+	}
+	for i, want := range fixtureWays {
+		got := ways[i]
+		if got.id != want.id || len(got.refs) != len(want.refs) {
+			t.Fatalf("way %d = %d %v, want %d %v", i, got.id, got.refs, want.id, want.refs)
+		}
+		for j := range want.refs {
+			if got.refs[j] != want.refs[j] {
+				t.Errorf("way %d has refs %v, want %v", want.id, got.refs, want.refs)
+			}
+		}
+		for key, value := range want.tags {
+			if got.tags[key] != value {
+				t.Errorf("way %d has tags %v, want %v", want.id, got.tags, want.tags)
+			}
+		}
+	}
+}
+
+func TestLoadGraphKeepsOnlyWalkableWays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.osm.pbf")
+	if err := os.WriteFile(path, encodePBF(t, fixtureNodes, fixtureWays), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	graph, err := LoadGraph(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// footway 100-101-95 and primary with foot=yes 95-102-103; the motorway, the residential road closed to
+	// pedestrians and the building are skipped, and so is the node 200 which no walkable way uses
+	if graph.NodeCount() != 5 || graph.EdgeCount() != 4 {
+		t.Errorf("got %d nodes and %d edges, want 5 and 4", graph.NodeCount(), graph.EdgeCount())
+	}
+
+	router := NewRouter(graph, DefaultWalkingSpeed)
+	start, end := Coordinate{Lat: 50.0600, Lon: 19.9300}, Coordinate{Lat: 50.0640, Lon: 19.9330}
+	route, err := router.Route(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route.Geometry) != 2+5 {
+		t.Errorf("route passes %d points, want the 5 nodes between both ends", len(route.Geometry))
+	}
+}
+
+func TestLoadGraphWithoutWalkableWays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.osm.pbf")
+	ways := []testWay{{id: 1, refs: []int64{100, 101}, tags: map[string]string{"highway": "motorway"}}}
+	if err := os.WriteFile(path, encodePBF(t, fixtureNodes, ways), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGraph(path); err == nil {
+		t.Error("extract without walkable ways was accepted")
+	}
+}
+
+func TestDecompressBlobRejectsTooLargeBlobs(t *testing.T) {
+	data := make([]byte, maxBlobSize+1)
+	if _, err := decompressBlob(zlibBlob(t, data, 0)); err == nil {
+		t.Error("blob larger than the maximum size was truncated instead of rejected")
+	}
+	if _, err := decompressBlob(zlibBlob(t, []byte{1}, maxBlobSize+1)); err == nil {
+		t.Error("blob declaring a size larger than the maximum was accepted")
+	}
+	blob, err := decompressBlob(zlibBlob(t, data[:maxBlobSize], maxBlobSize))
+	if err != nil || len(blob) != maxBlobSize {
+		t.Errorf("blob of the maximum size: got %d bytes, %v", len(blob), err)
+	}
+}
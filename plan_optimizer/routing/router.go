@@ -0,0 +1,275 @@
+package routing
+
+import (
+	"container/heap"
+	"container/list"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// DefaultWalkingSpeed is the pedestrian speed in km/h used to turn path lengths into durations.
+const DefaultWalkingSpeed = 5.0
+
+// DefaultCacheSize is the number of paths a Router keeps. Paths carry their whole geometry, so the cache is bounded
+// to keep a long running server from growing with every pair of points it was ever asked about.
+const DefaultCacheSize = 20000
+
+type Path struct {
+	DistanceMeters  float64
+	DurationMinutes int
+	Geometry        []Coordinate
+}
+
+// Router computes shortest walking paths on a Graph with A*. The most recently used results are cached, so it is
+// cheap to ask for the same pair of points many times, which is what the optimizer does. A Router is safe for
+// concurrent use.
+type Router struct {
+	graph        *Graph
+	walkingSpeed float64
+
+	mutex     sync.Mutex
+	cache     map[pathKey]*list.Element
+	recent    *list.List // cachedPath entries, the most recently used first
+	cacheSize int
+
+	states sync.Pool
+}
+
+type pathKey struct {
+	from Coordinate
+	to   Coordinate
+}
+
+type cachedPath struct {
+	key  pathKey
+	path Path
+}
+
+func NewRouter(graph *Graph, walkingSpeed float64) *Router {
+	if walkingSpeed <= 0 {
+		walkingSpeed = DefaultWalkingSpeed
+	}
+	r := &Router{
+		graph:        graph,
+		walkingSpeed: walkingSpeed,
+		cache:        make(map[pathKey]*list.Element),
+		recent:       list.New(),
+		cacheSize:    DefaultCacheSize,
+	}
+	r.states.New = func() interface{} {
+		return newSearchState(len(graph.coordinates))
+	}
+	return r
+}
+
+// LoadRouter builds a Router from an .osm.pbf extract.
+func LoadRouter(path string, walkingSpeed float64) (*Router, error) {
+	graph, err := LoadGraph(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewRouter(graph, walkingSpeed), nil
+}
+
+func (r *Router) Graph() *Graph {
+	return r.graph
+}
+
+// Route returns the shortest walking path between two points. Both points are snapped to the nearest graph node
+// and the snapping distance is included in the path length.
+func (r *Router) Route(from, to Coordinate) (Path, error) {
+	key := pathKey{from: from, to: to}
+	r.mutex.Lock()
+	path, ok := r.cached(key)
+	if !ok {
+		// the graph is undirected, so the reversed path is just as good
+		if reversed, found := r.cached(pathKey{from: to, to: from}); found {
+			path, ok = reversePath(reversed), true
+		}
+	}
+	r.mutex.Unlock()
+	if ok {
+		return path, nil
+	}
+
+	path, err := r.route(from, to)
+	if err != nil {
+		return Path{}, err
+	}
+	r.mutex.Lock()
+	r.store(key, path)
+	r.mutex.Unlock()
+	return path, nil
+}
+
+// cached returns the cached path and marks it as the most recently used. The caller holds the mutex.
+func (r *Router) cached(key pathKey) (Path, bool) {
+	element, ok := r.cache[key]
+	if !ok {
+		return Path{}, false
+	}
+	r.recent.MoveToFront(element)
+	return element.Value.(*cachedPath).path, true
+}
+
+// store caches the path and evicts the least recently used paths beyond the cache size. The caller holds the mutex.
+func (r *Router) store(key pathKey, path Path) {
+	if element, ok := r.cache[key]; ok {
+		// another goroutine routed the same pair in the meantime
+		r.recent.MoveToFront(element)
+		return
+	}
+	r.cache[key] = r.recent.PushFront(&cachedPath{key: key, path: path})
+	for r.recent.Len() > r.cacheSize {
+		oldest := r.recent.Back()
+		r.recent.Remove(oldest)
+		delete(r.cache, oldest.Value.(*cachedPath).key)
+	}
+}
+
+func (r *Router) route(from, to Coordinate) (Path, error) {
+	if from == to {
+		return Path{Geometry: []Coordinate{from, to}}, nil
+	}
+	source, sourceSnap, err := r.graph.nearestNode(from)
+	if err != nil {
+		return Path{}, err
+	}
+	target, targetSnap, err := r.graph.nearestNode(to)
+	if err != nil {
+		return Path{}, err
+	}
+
+	nodes, length, err := r.shortestPath(source, target)
+	if err != nil {
+		return Path{}, fmt.Errorf("routing: no path from (%f, %f) to (%f, %f): %w", from.Lat, from.Lon, to.Lat, to.Lon, err)
+	}
+
+	geometry := make([]Coordinate, 0, len(nodes)+2)
+	geometry = append(geometry, from)
+	for _, node := range nodes {
+		geometry = append(geometry, r.graph.coordinates[node])
+	}
+	geometry = append(geometry, to)
+
+	distance := sourceSnap + length + targetSnap
+	return Path{
+		DistanceMeters:  distance,
+		DurationMinutes: int(math.Ceil(distance / (r.walkingSpeed * 1000.0 / 60.0))),
+		Geometry:        geometry,
+	}, nil
+}
+
+func reversePath(path Path) Path {
+	geometry := make([]Coordinate, len(path.Geometry))
+	for i, c := range path.Geometry {
+		geometry[len(geometry)-1-i] = c
+	}
+	path.Geometry = geometry
+	return path
+}
+
+// searchState holds the per query A* arrays. Only the touched entries are reset, so states can be reused between
+// queries without clearing arrays as large as the whole graph.
+type searchState struct {
+	distance []float64
+	previous []int32
+	closed   []bool
+	touched  []int32
+	queue    nodeQueue
+}
+
+func newSearchState(size int) *searchState {
+	s := &searchState{
+		distance: make([]float64, size),
+		previous: make([]int32, size),
+		closed:   make([]bool, size),
+	}
+	for i := range s.distance {
+		s.distance[i] = math.Inf(1)
+		s.previous[i] = -1
+	}
+	return s
+}
+
+func (s *searchState) reset() {
+	for _, node := range s.touched {
+		s.distance[node] = math.Inf(1)
+		s.previous[node] = -1
+		s.closed[node] = false
+	}
+	s.touched = s.touched[:0]
+	s.queue = s.queue[:0]
+}
+
+type queueItem struct {
+	node     int32
+	priority float64
+}
+
+type nodeQueue []queueItem
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(queueItem)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// shortestPath runs A* with the great-circle distance as heuristic, which never overestimates a walking distance.
+func (r *Router) shortestPath(source, target int32) ([]int32, float64, error) {
+	g := r.graph
+	state := r.states.Get().(*searchState)
+	defer func() {
+		state.reset()
+		r.states.Put(state)
+	}()
+
+	goal := g.coordinates[target]
+	state.distance[source] = 0
+	state.touched = append(state.touched, source)
+	heap.Push(&state.queue, queueItem{node: source, priority: distanceMeters(g.coordinates[source], goal)})
+
+	for state.queue.Len() > 0 {
+		current := heap.Pop(&state.queue).(queueItem).node
+		if current == target {
+			break
+		}
+		if state.closed[current] {
+			continue
+		}
+		state.closed[current] = true
+		for e := g.offsets[current]; e < g.offsets[current+1]; e++ {
+			next := g.targets[e]
+			if state.closed[next] {
+				continue
+			}
+			candidate := state.distance[current] + float64(g.lengths[e])
+			if candidate < state.distance[next] {
+				if math.IsInf(state.distance[next], 1) {
+					state.touched = append(state.touched, next)
+				}
+				state.distance[next] = candidate
+				state.previous[next] = current
+				heap.Push(&state.queue, queueItem{node: next, priority: candidate + distanceMeters(g.coordinates[next], goal)})
+			}
+		}
+	}
+
+	if math.IsInf(state.distance[target], 1) {
+		return nil, 0, fmt.Errorf("target unreachable")
+	}
+	nodes := make([]int32, 0)
+	for node := target; node >= 0; node = state.previous[node] {
+		nodes = append(nodes, node)
+	}
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	return nodes, state.distance[target], nil
+}
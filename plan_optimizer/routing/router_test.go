@@ -0,0 +1,166 @@
+package routing
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomGraph places nodes on a jittered grid and joins random pairs of near nodes. Edges are never shorter than
+// the great-circle distance, like the edges of a real street network, so the A* heuristic stays admissible.
+func randomGraph(random *rand.Rand, size int) *Graph {
+	coordinates := make([]Coordinate, size)
+	side := int(math.Ceil(math.Sqrt(float64(size))))
+	for i := range coordinates {
+		coordinates[i] = Coordinate{
+			Lat: 50.05 + 0.001*float64(i/side) + 0.0004*random.Float64(),
+			Lon: 19.93 + 0.001*float64(i%side) + 0.0004*random.Float64(),
+		}
+	}
+	edges := make([]graphEdge, 0)
+	for from := range coordinates {
+		for to := from + 1; to < size; to++ {
+			if distanceMeters(coordinates[from], coordinates[to]) < 200 && random.Float64() < 0.6 {
+				length := distanceMeters(coordinates[from], coordinates[to]) * (1 + random.Float64())
+				edges = append(edges, graphEdge{from: int32(from), to: int32(to), length: float32(length)})
+			}
+		}
+	}
+	return newGraph(coordinates, edges)
+}
+
+// dijkstra returns the distances from the source to all nodes.
+func dijkstra(g *Graph, source int32) []float64 {
+	distance := make([]float64, len(g.coordinates))
+	done := make([]bool, len(g.coordinates))
+	for i := range distance {
+		distance[i] = math.Inf(1)
+	}
+	distance[source] = 0
+	for {
+		current := int32(-1)
+		for node := range distance {
+			if !done[node] && !math.IsInf(distance[node], 1) && (current < 0 || distance[node] < distance[current]) {
+				current = int32(node)
+			}
+		}
+		if current < 0 {
+			return distance
+		}
+		done[current] = true
+		for e := g.offsets[current]; e < g.offsets[current+1]; e++ {
+			if candidate := distance[current] + float64(g.lengths[e]); candidate < distance[g.targets[e]] {
+				distance[g.targets[e]] = candidate
+			}
+		}
+	}
+}
+
+func edgeLength(g *Graph, from, to int32) (float64, bool) {
+	best := math.Inf(1)
+	for e := g.offsets[from]; e < g.offsets[from+1]; e++ {
+		if g.targets[e] == to && float64(g.lengths[e]) < best {
+			best = float64(g.lengths[e])
+		}
+	}
+	return best, !math.IsInf(best, 1)
+}
+
+func TestShortestPathMatchesDijkstra(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for instance := 0; instance < 5; instance++ {
+		graph := randomGraph(random, 40)
+		router := NewRouter(graph, DefaultWalkingSpeed)
+		for source := int32(0); source < int32(graph.NodeCount()); source++ {
+			expected := dijkstra(graph, source)
+			for target := int32(0); target < int32(graph.NodeCount()); target++ {
+				nodes, length, err := router.shortestPath(source, target)
+				if math.IsInf(expected[target], 1) {
+					if err == nil {
+						t.Fatalf("path %d-%d found in a disconnected graph", source, target)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("path %d-%d: %v", source, target, err)
+				}
+				if math.Abs(length-expected[target]) > 1e-6 {
+					t.Fatalf("path %d-%d has length %f, Dijkstra found %f", source, target, length, expected[target])
+				}
+				if nodes[0] != source || nodes[len(nodes)-1] != target {
+					t.Fatalf("path %d-%d goes %v", source, target, nodes)
+				}
+				total := 0.0
+				for i := 1; i < len(nodes); i++ {
+					edge, ok := edgeLength(graph, nodes[i-1], nodes[i])
+					if !ok {
+						t.Fatalf("path %d-%d uses the missing edge %d-%d", source, target, nodes[i-1], nodes[i])
+					}
+					total += edge
+				}
+				if math.Abs(total-length) > 1e-6 {
+					t.Fatalf("path %d-%d has edges of length %f, reported %f", source, target, total, length)
+				}
+			}
+		}
+	}
+}
+
+func TestRouteIsCachedInBothDirections(t *testing.T) {
+	graph := randomGraph(rand.New(rand.NewSource(2)), 30)
+	router := NewRouter(graph, DefaultWalkingSpeed)
+	from, to := graph.coordinates[0], graph.coordinates[len(graph.coordinates)-1]
+	there, err := router.Route(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := router.Route(to, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if there.DistanceMeters != back.DistanceMeters || len(there.Geometry) != len(back.Geometry) ||
+		back.Geometry[0] != to || back.Geometry[len(back.Geometry)-1] != from {
+		t.Errorf("reversed route %+v does not match %+v", back, there)
+	}
+	minutes := int(math.Ceil(there.DistanceMeters / (DefaultWalkingSpeed * 1000.0 / 60.0)))
+	if there.DurationMinutes != minutes {
+		t.Errorf("duration %d min, want %d", there.DurationMinutes, minutes)
+	}
+}
+
+func TestRouteCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	graph := randomGraph(rand.New(rand.NewSource(3)), 30)
+	router := NewRouter(graph, DefaultWalkingSpeed)
+	router.cacheSize = 3
+	points := graph.coordinates
+	route := func(from, to int) {
+		t.Helper()
+		if _, err := router.Route(points[from], points[to]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := func(from, to int) bool {
+		_, ok := router.cache[pathKey{from: points[from], to: points[to]}]
+		return ok
+	}
+
+	route(0, 1)
+	route(0, 2)
+	route(0, 3)
+	// the reversed lookup uses the first path, so the second one is the least recently used
+	route(1, 0)
+	route(0, 4)
+	if len(router.cache) != 3 || router.recent.Len() != 3 {
+		t.Fatalf("cache of size 3 holds %d paths", len(router.cache))
+	}
+	if cached(0, 2) || !cached(0, 1) || !cached(0, 3) || !cached(0, 4) {
+		t.Errorf("cache did not evict the least recently used path")
+	}
+
+	for to := 5; to < len(points); to++ {
+		route(0, to)
+	}
+	if len(router.cache) != 3 || router.recent.Len() != 3 {
+		t.Errorf("cache of size 3 grew to %d paths", len(router.cache))
+	}
+}
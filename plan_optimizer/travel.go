@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	ga "genetic_algorithm"
+	"os"
+	"routing"
+	"strconv"
+)
+
+// osmTravel routes legs over the pedestrian graph built from a local OpenStreetMap extract. Points that cannot be
+// routed (outside the extract or in a disconnected area) fall back to the haversine estimate.
+type osmTravel struct {
+	router *routing.Router
+}
+
+func (o osmTravel) Travel(from *ga.POI, to *ga.POI) ga.Leg {
+	path, err := o.router.Route(routing.Coordinate{Lat: from.Lat, Lon: from.Lon}, routing.Coordinate{Lat: to.Lat, Lon: to.Lon})
+	if err != nil {
+		return ga.HaversineTravel{}.Travel(from, to)
+	}
+	geometry := make([]ga.Coordinate, len(path.Geometry))
+	for i, c := range path.Geometry {
+		geometry[i] = ga.Coordinate{Lat: c.Lat, Lon: c.Lon}
+	}
+	return ga.Leg{
+		DistanceMeters:  path.DistanceMeters,
+		DurationMinutes: path.DurationMinutes,
+		Mode:            "walking",
+		Geometry:        geometry,
+	}
+}
+
+// configureTravel enables OSM routing when OSM_PBF_PATH points to an extract. WALKING_SPEED_KMH optionally
+// overrides the walking speed.
+func configureTravel() {
+	pbfPath := os.Getenv("OSM_PBF_PATH")
+	if pbfPath == "" {
+		fmt.Println("OSM_PBF_PATH not set, using haversine travel times")
+		return
+	}
+	walkingSpeed := routing.DefaultWalkingSpeed
+	if value := os.Getenv("WALKING_SPEED_KMH"); value != "" {
+		if speed, err := strconv.ParseFloat(value, 64); err == nil && speed > 0 {
+			walkingSpeed = speed
+		}
+	}
+	router, err := routing.LoadRouter(pbfPath, walkingSpeed)
+	if err != nil {
+		fmt.Println("Could not load OSM extract, using haversine travel times:", err)
+		return
+	}
+	fmt.Printf("Loaded pedestrian graph from %s: %d nodes, %d edges\n", pbfPath,
+		router.Graph().NodeCount(), router.Graph().EdgeCount())
+	ga.SetTravelProvider(osmTravel{router: router})
+}